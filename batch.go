@@ -0,0 +1,188 @@
+package atomicmap
+
+const (
+	// batchChunkSize is how much keys a batch operation processes between
+	// re-registrations as a writer. It prevents the growing procedure (and
+	// so other writers) from waiting for the whole batch.
+	batchChunkSize = 4096
+)
+
+type hashedKey struct {
+	preHashValue       uint64
+	hashValue          uint64
+	typeID             uint8
+	preHashValueIsFull bool
+	err                error // the key is rejected (see preHashKey)
+}
+
+func (m *openAddressGrowingMap) hashKeys(keys []Key) []hashedKey {
+	hashedKeys := make([]hashedKey, len(keys))
+	for idx, key := range keys {
+		hashedKey := &hashedKeys[idx]
+		hashedKey.preHashValue, hashedKey.typeID, hashedKey.preHashValueIsFull, hashedKey.err = m.preHashKey(key)
+		if hashedKey.err != nil {
+			continue
+		}
+		hashedKey.hashValue = m.completeHash(hashedKey.preHashValue, hashedKey.typeID)
+		if !hashedKey.preHashValueIsFull {
+			hashedKey.typeID = 0
+		}
+	}
+	return hashedKeys
+}
+
+// reserve grows the map to fit "amount" more keys without further growing.
+func (m *openAddressGrowingMap) reserve(amount uint64) error {
+	expectedSize := uint64(float64(m.BusySlots()+amount)/m.growAtFullness) + 1
+	if expectedSize <= m.size() {
+		if float64(m.occupiedSlots()+amount)/float64(m.size()) >= m.growAtFullness {
+			return m.dropTombstones()
+		}
+		return nil
+	}
+	return m.growTo(powerOfTwoGE(expectedSize))
+}
+
+// SetMany is the same as calling Set for each key and value, but cheaper:
+// the map is grown once beforehand, all the keys are hashed up front and
+// the thread-safety overhead is paid once per chunk of keys instead of
+// once per key. It returns the errors of each key (nil if there were no
+// errors at all).
+func (m *openAddressGrowingMap) SetMany(keys []Key, values []interface{}) []error {
+	if len(keys) != len(values) {
+		panic(`len(keys) != len(values)`)
+	}
+	hashedKeys := m.hashKeys(keys)
+	m.reserve(uint64(len(keys)))
+
+	var errs []error
+	for chunkStart := 0; chunkStart < len(keys); chunkStart += batchChunkSize {
+		chunkEnd := chunkStart + batchChunkSize
+		if chunkEnd > len(keys) {
+			chunkEnd = len(keys)
+		}
+		if m.threadSafety {
+			if err := m.enterWrite(int32(chunkEnd - chunkStart)); err != nil {
+				if errs == nil {
+					errs = make([]error, len(keys))
+				}
+				for idx := chunkStart; idx < len(keys); idx++ {
+					errs[idx] = err
+				}
+				return errs
+			}
+		}
+		for idx := chunkStart; idx < chunkEnd; idx++ {
+			key, value, hashedKey := keys[idx], values[idx], &hashedKeys[idx]
+			if hashedKey.err != nil {
+				if errs == nil {
+					errs = make([]error, len(keys))
+				}
+				errs[idx] = hashedKey.err
+				continue
+			}
+			m.setByHashValue(hashedKey.preHashValue, hashedKey.typeID, hashedKey.preHashValueIsFull, hashedKey.hashValue, func(slot *mapSlot) bool {
+				return m.isEqualKey(slot.entry.key, key)
+			}, func(slot *mapSlot) {
+				slot.entry.key = key
+			}, func(slot *mapSlot) bool {
+				slot.entry.setValue(value)
+				return true
+			})
+		}
+		if m.threadSafety {
+			m.leaveWrite(int32(chunkEnd - chunkStart))
+		}
+	}
+	if !m.isEnoughFreeSpace() {
+		m.makeFreeSpace()
+	}
+	return errs
+}
+
+// GetMany is the same as calling Get for each key, but all the keys are
+// hashed up front. It returns the values and the errors of each key (errs
+// is nil if all the keys were found).
+func (m *openAddressGrowingMap) GetMany(keys []Key) (values []interface{}, errs []error) {
+	values = make([]interface{}, len(keys))
+	if m.BusySlots() == 0 {
+		errs = make([]error, len(keys))
+		for idx, key := range keys {
+			if errs[idx] = m.checkKey(key); errs[idx] == nil {
+				errs[idx] = NotFound
+			}
+		}
+		return
+	}
+	hashedKeys := m.hashKeys(keys)
+
+	for idx, key := range keys {
+		hashedKey := &hashedKeys[idx]
+		if hashedKey.err != nil {
+			if errs == nil {
+				errs = make([]error, len(keys))
+			}
+			errs[idx] = hashedKey.err
+			continue
+		}
+		value, err := m.getByHashValue(hashedKey.preHashValue, hashedKey.typeID, hashedKey.hashValue, func(slot *mapSlot) bool {
+			return m.isEqualKey(slot.entry.key, key)
+		})
+		if err != nil {
+			if errs == nil {
+				errs = make([]error, len(keys))
+			}
+			errs[idx] = err
+			continue
+		}
+		values[idx] = value
+	}
+	return
+}
+
+// UnsetMany is the same as calling Unset for each key, but all the keys are
+// hashed up front and the thread-safety overhead is paid once per chunk of
+// keys. It returns the errors of each key (nil if there were no errors at
+// all).
+func (m *openAddressGrowingMap) UnsetMany(keys []Key) (errs []error) {
+	hashedKeys := m.hashKeys(keys)
+
+	for chunkStart := 0; chunkStart < len(keys); chunkStart += batchChunkSize {
+		chunkEnd := chunkStart + batchChunkSize
+		if chunkEnd > len(keys) {
+			chunkEnd = len(keys)
+		}
+		if m.threadSafety {
+			m.enterWriteSection(1)
+		}
+		for idx := chunkStart; idx < chunkEnd; idx++ {
+			hashedKey, key := &hashedKeys[idx], keys[idx]
+			if hashedKey.err != nil {
+				if errs == nil {
+					errs = make([]error, len(keys))
+				}
+				errs[idx] = hashedKey.err
+				continue
+			}
+			var slot *mapSlot
+			if m.BusySlots() != 0 {
+				slot, _ = m.lockSlotByHashValue(hashedKey.preHashValue, hashedKey.typeID, hashedKey.hashValue, func(slot *mapSlot) bool {
+					return m.isEqualKey(slot.entry.key, key)
+				})
+			}
+			if slot == nil {
+				if errs == nil {
+					errs = make([]error, len(keys))
+				}
+				errs[idx] = NotFound
+				continue
+			}
+			m.removeLockedSlot(slot)
+		}
+		if m.threadSafety {
+			m.leaveWriteSection(1)
+		}
+	}
+	m.shrinkIfNeeded()
+	return
+}
@@ -0,0 +1,115 @@
+package atomicmap
+
+import (
+	"sync/atomic"
+)
+
+// AddUint64 atomically adds delta to the uint64 counter of the key and
+// returns the new value. If there's no such key, the counter is created with
+// value delta. If the key has a value of another type, WrongValueType is
+// returned (except uint64 values set by Set(): they become counters).
+//
+// Counters are stored inline in the slot: incrementing an existing counter
+// doesn't allocate and doesn't lock the slot. Get() returns the counter as an
+// uint64 value.
+func (m *openAddressGrowingMap) AddUint64(key Key, delta uint64) (newValue uint64, err error) {
+	return m.addCounter(key, counterType_uint64, delta)
+}
+
+// AddInt64 is the same as AddUint64, but for int64 counters.
+func (m *openAddressGrowingMap) AddInt64(key Key, delta int64) (newValue int64, err error) {
+	newValueUint64, err := m.addCounter(key, counterType_int64, uint64(delta))
+	return int64(newValueUint64), err
+}
+
+func (entry *mapEntry) addCounter(expectedCounterType counterType, delta uint64) (uint64, error) {
+	if entry.counterType == expectedCounterType {
+		return atomic.AddUint64(&entry.counterValue, delta), nil
+	}
+	if entry.counterType != counterType_none || entry.bytesValue != nil {
+		return 0, WrongValueType
+	}
+
+	// Converting a value set by Set() to a counter
+	var oldValue uint64
+	switch expectedCounterType {
+	case counterType_uint64:
+		value, ok := entry.value.(uint64)
+		if !ok {
+			return 0, WrongValueType
+		}
+		oldValue = value
+	case counterType_int64:
+		value, ok := entry.value.(int64)
+		if !ok {
+			return 0, WrongValueType
+		}
+		oldValue = uint64(value)
+	}
+	entry.setValue(nil)
+	entry.counterValue, entry.counterType = oldValue+delta, expectedCounterType
+	return entry.counterValue, nil
+}
+
+func (m *openAddressGrowingMap) addCounter(key Key, expectedCounterType counterType, delta uint64) (newValue uint64, err error) {
+	preHashValue, typeID, preHashValueIsFull, err := m.preHashKey(key)
+	if err != nil {
+		return 0, err
+	}
+
+	// Fast path: the counter already exists, so it's enough to hold a reader
+	// on the slot (to prevent its conversion or removal) and to use atomic
+	// addition.
+	if m.BusySlots() != 0 {
+		if m.threadSafety {
+			m.enterWriteSection(1)
+		}
+		hashValue := m.completeHash(preHashValue, typeID)
+		var fastKey uint64
+		var fastKeyType uint8
+		if preHashValueIsFull {
+			fastKey, fastKeyType = preHashValue, typeID
+		}
+		slot := m.getSlotByHashValue(fastKey, fastKeyType, hashValue, func(slot *mapSlot) bool {
+			return m.isEqualKey(slot.entry.key, key)
+		})
+		isDone := false
+		if slot != nil {
+			if slot.entry.counterType == expectedCounterType {
+				newValue = atomic.AddUint64(&slot.entry.counterValue, delta)
+				isDone = true
+			}
+			if m.threadSafety {
+				slot.decreaseReaders()
+			}
+		}
+		if m.threadSafety {
+			m.leaveWriteSection(1)
+		}
+		if isDone {
+			return newValue, nil
+		}
+	}
+
+	// Slow path: creating the counter (or converting the value to it)
+	isNewSlot := false
+	setErr := m.set(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return m.isEqualKey(slot.entry.key, key)
+	}, func(slot *mapSlot) {
+		slot.entry.key = key
+		isNewSlot = true
+	}, func(slot *mapSlot) bool {
+		if isNewSlot {
+			slot.entry.setValue(nil)
+			slot.entry.counterValue, slot.entry.counterType = delta, expectedCounterType
+			newValue = delta
+			return true
+		}
+		newValue, err = slot.entry.addCounter(expectedCounterType, delta)
+		return true
+	})
+	if setErr != nil {
+		return 0, setErr
+	}
+	return newValue, err
+}
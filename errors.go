@@ -11,4 +11,12 @@ var (
 	AlreadyGrowing  = errors.AlreadyGrowing
 	ForbiddenToGrow = errors.ForbiddenToGrow
 	ConditionFailed = errors.ConditionFailed
+	WrongValueType  = errors.WrongValueType
+	WrongKeyType    = errors.WrongKeyType
+	InvalidArgument = errors.InvalidArgument
+	InvalidOption   = errors.InvalidOption
+
+	CannotRegisterKeyType = errors.CannotRegisterKeyType
 )
+
+type WrongKeyTypeError = errors.WrongKeyTypeError
@@ -2,6 +2,7 @@ package errors
 
 import (
 	"fmt"
+	"reflect"
 )
 
 var (
@@ -11,4 +12,25 @@ var (
 	AlreadyGrowing  = fmt.Errorf("already growing")
 	ForbiddenToGrow = fmt.Errorf("forbidden to grow")
 	ConditionFailed = fmt.Errorf("condition function returned \"false\"")
+	WrongValueType  = fmt.Errorf("the value has a wrong type")
+	WrongKeyType    = fmt.Errorf("the key has a wrong type")
+	InvalidArgument = fmt.Errorf("invalid argument")
+	InvalidOption   = fmt.Errorf("invalid option")
+
+	CannotRegisterKeyType = fmt.Errorf("cannot register the key type")
 )
+
+// WrongKeyTypeError is returned if a key of another type is passed to a map
+// with the key type pinned. errors.Is(err, WrongKeyType) is true for it.
+type WrongKeyTypeError struct {
+	Expected reflect.Type
+	Actual   reflect.Type
+}
+
+func (err *WrongKeyTypeError) Error() string {
+	return fmt.Sprintf("%v: expected %v, got %v", WrongKeyType, err.Expected, err.Actual)
+}
+
+func (err *WrongKeyTypeError) Is(target error) bool {
+	return target == WrongKeyType
+}
@@ -1,9 +1,11 @@
 module github.com/xaionaro-go/atomicmap
 
-go 1.13
+go 1.23
 
 require (
 	github.com/OneOfOne/xxhash v1.2.7
 	github.com/cornelk/hashmap v1.0.1
 	github.com/xaionaro-go/spinlock v0.0.0-20190309154744-55278e21e817
 )
+
+require github.com/dchest/siphash v1.1.0 // indirect
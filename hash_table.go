@@ -0,0 +1,685 @@
+package atomicmap
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync/atomic"
+	"unsafe"
+)
+
+// hashTable is the open addressing hash table shared by Map and Typed: the
+// storage, the slot state machine, the probing and the growing procedure.
+// It's parametrized by the entry of a slot (the key and the value, see
+// storageSlot); the keys are hashed and compared by the map
+// implementations, which pass the hash values and the key comparison
+// functions here.
+type hashTable[E any] struct {
+	initialSize uint64
+
+	mapControl
+
+	storage *storage[E]
+}
+
+// init configures the table by the options and allocates the initial
+// storage.
+func (m *hashTable[E]) init(cfg *options) error {
+	m.initialSize = cfg.blockSize
+	cfg.setupMapControl(&m.mapControl)
+	if err := m.growTo(cfg.blockSize); err != nil {
+		return err
+	}
+	m.SetForbidGrowing(cfg.forbidGrowing)
+	return nil
+}
+
+func (m *hashTable[E]) isEnoughFreeSpace() bool {
+	return float64(m.occupiedSlots())/float64(m.size()) < m.growAtFullness
+}
+
+// setHashed finds the slot of the key (or a free slot if there's no such key
+// yet) and calls setValue on it while the slot is locked. If setValue returns
+// false, the key is removed (or is not added if it's a new one). setKey is
+// called only for a new slot. typeID is ignored if the pre-hash is not full.
+func (m *hashTable[E]) setHashed(preHashValue uint64, typeID uint8, preHashValueIsFull bool, hashValue uint64, compareKey func(*storageSlot[E]) bool, setKey func(*storageSlot[E]), setValue func(*storageSlot[E]) bool) error {
+	/*if m.currentSize == len(m.storage) {
+		return NoSpaceLeft
+	}*/
+	isReturned := false
+	if m.threadSafety {
+		if err := m.enterWrite(1); err != nil {
+			return err
+		}
+		//m.increaseConcurrency()
+		defer func() {
+			if !isReturned { // setValue panicked
+				m.leaveWrite(1)
+			}
+		}()
+	}
+
+	isAdded := m.setByHashValue(preHashValue, typeID, preHashValueIsFull, hashValue, compareKey, setKey, setValue)
+	isReturned = true
+
+	if m.threadSafety {
+		m.leaveWrite(1)
+		//m.decreaseConcurrency()
+	}
+	if isAdded {
+		if !m.isEnoughFreeSpace() {
+			if err := m.makeFreeSpace(); err != nil {
+				return nil
+			}
+		}
+	} else {
+		m.shrinkIfNeeded()
+	}
+	return nil
+}
+
+// enterWrite registers a writer which may add up to "amount" keys (see
+// mapControl.enterWriteSection) and makes free space if there's not enough
+// of it.
+//
+// The amount is added to writeConcurrency before checking the free space,
+// so concurrent writers see each other's reservations.
+func (m *hashTable[E]) enterWrite(amount int32) error {
+	for {
+		m.enterWriteSection(amount)
+		if m.isEnoughFreeSpace() {
+			return nil
+		}
+		m.leaveWriteSection(amount)
+		if err := m.makeFreeSpace(); err != nil && err != AlreadyGrowing {
+			return err
+		}
+	}
+}
+
+func (m *hashTable[E]) leaveWrite(amount int32) {
+	m.leaveWriteSection(amount)
+}
+
+// setByHashValue is the probe loop of setHashed. It should be called between
+// enterWrite and leaveWrite (if the map is thread-safe). It returns true if
+// a new key was added.
+func (m *hashTable[E]) setByHashValue(preHashValue uint64, typeID uint8, preHashValueIsFull bool, hashValue uint64, compareKey func(*storageSlot[E]) bool, setKey func(*storageSlot[E]), setValue func(*storageSlot[E]) bool) bool {
+	if !preHashValueIsFull {
+		typeID = 0
+	}
+	m.helpMigration()
+	m.migrateKey(preHashValue, typeID, hashValue, compareKey)
+	stor := m.lastStorage()
+	idxValue := stor.getIdx(hashValue)
+
+	var slot *storageSlot[E]
+	var removedSlot *storageSlot[E] // the first removed slot on the way, it's reused if the key is not found
+	var removedSlotSlid uint64
+	startIdxValue := idxValue
+	slid := uint64(0)
+	for { // Going forward through the storage while a collision (to find a free slots)
+		slot = &stor.items[idxValue]
+		isSetStatus := slot.IsSet()
+		if isSetStatus == isSet_notSet {
+			// It's the end of the chain, so there's no such key in the map.
+			// We cannot take a removed slot before this point: the key
+			// could be somewhere further in the chain.
+			if removedSlot != nil {
+				if removedSlot.isSet.CompareAndSwap(isSet_removed, isSet_setting) {
+					atomic.AddInt64(&m.removedSlots, -1)
+					removedSlotIdx := (startIdxValue + removedSlotSlid) % stor.size()
+					if m.threadSafety && !m.isChainFreeOfKey(stor, startIdxValue, removedSlotIdx, preHashValue, typeID, hashValue, compareKey) {
+						// The tombstone could be freed and taken again while
+						// we were walking the chain (ABA), so the key may be
+						// already added to another slot. Releasing the
+						// tombstone and checking the chain from the beginning.
+						atomic.AddInt64(&m.removedSlots, 1)
+						removedSlot.isSet.Store(isSet_removed)
+						idxValue, slid, removedSlot = startIdxValue, 0, nil
+						continue
+					}
+					slot, slid = removedSlot, removedSlotSlid
+					break
+				}
+				// Somebody has just taken the removed slot (probably for
+				// the same key), so checking the chain from the beginning.
+				idxValue, slid, removedSlot = startIdxValue, 0, nil
+				continue
+			}
+			if slot.isSet.CompareAndSwap(isSet_notSet, isSet_setting) {
+				break
+			}
+			continue // try again
+		}
+		if isSetStatus == isSet_removed {
+			if removedSlot == nil {
+				removedSlot, removedSlotSlid = slot, slid
+			}
+		} else {
+			if m.threadSafety {
+				if !slot.setIsUpdating() {
+					continue // try again
+				}
+			}
+			if slot.hashValue == hashValue {
+				var isEqualKey bool
+				if typeID != 0 || slot.fastKeyType != 0 {
+					isEqualKey = slot.fastKey == preHashValue && slot.fastKeyType == typeID
+				} else {
+					isEqualKey = compareKey(slot)
+				}
+
+				if isEqualKey {
+					if m.threadSafety {
+						slot.waitForReadersOut()
+					}
+					newIsSet := isSet_set
+					if !m.callLocked(slot, false, setValue) {
+						slot.clearKey()
+						atomic.AddInt64(&m.busySlots, -1)
+						atomic.AddInt64(&m.removedSlots, 1)
+						newIsSet = isSet_removed
+					}
+					slot.isSet.Store(newIsSet)
+					return false
+				}
+			}
+			slot.isSet.Store(isSet_set)
+		}
+		slid++
+		idxValue++
+		if idxValue >= stor.size() {
+			idxValue = 0
+		}
+		if slid > stor.size() {
+			panic(fmt.Errorf("%v %v %v %v", slid, stor.size(), m.BusySlots(), m.isGrowing))
+		}
+	}
+
+	slot.hashValue = hashValue
+	if preHashValueIsFull {
+		slot.fastKey, slot.fastKeyType = preHashValue, typeID
+	} else {
+		slot.fastKey, slot.fastKeyType = 0, 0
+	}
+	setKey(slot)
+	if !m.callLocked(slot, true, setValue) {
+		// Cancelling the adding of the key. Other goroutines may already
+		// wait for this slot, so it's released as a removed one.
+		slot.clearKey()
+		atomic.AddInt64(&m.removedSlots, 1)
+		slot.isSet.Store(isSet_removed)
+		return false
+	}
+	slot.slid = slid
+	atomic.AddInt64(&m.busySlots, 1)
+	slot.isSet.Store(isSet_set)
+	return true
+}
+
+// isChainFreeOfKey walks the chain from idxValue to its end (skipping the
+// slot ownIdxValue, which is being set by us) and returns true if there's no
+// such key. It returns false if it cannot be verified without waiting for a
+// slot being set by another writer before ownIdxValue: that writer may wait
+// for our slot in turn.
+func (m *hashTable[E]) isChainFreeOfKey(stor *storage[E], idxValue, ownIdxValue uint64, preHashValue uint64, typeID uint8, hashValue uint64, compareKey func(*storageSlot[E]) bool) bool {
+	for slid := uint64(0); slid <= stor.size(); slid++ {
+		curIdxValue := idxValue
+		idxValue++
+		if idxValue >= stor.size() {
+			idxValue = 0
+		}
+		if curIdxValue == ownIdxValue {
+			continue
+		}
+		slot := &stor.items[curIdxValue]
+		var isSetStatus isSet
+		for {
+			isSetStatus = slot.tryIncreaseReaders()
+			if isSetStatus != isSet_setting && isSetStatus != isSet_updating {
+				break
+			}
+			if isSetStatus == isSet_setting && curIdxValue < ownIdxValue {
+				return false
+			}
+			runtime.Gosched()
+		}
+		switch isSetStatus {
+		case isSet_notSet:
+			return true
+		case isSet_removed, isSet_moved:
+			continue
+		}
+
+		isEqualKey := false
+		if slot.hashValue == hashValue {
+			if typeID != 0 || slot.fastKeyType != 0 {
+				isEqualKey = slot.fastKey == preHashValue && slot.fastKeyType == typeID
+			} else {
+				isEqualKey = compareKey(slot)
+			}
+		}
+		slot.decreaseReaders()
+		if isEqualKey {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *hashTable[E]) growTo(newSize uint64) error {
+	if err := m.checkResizeAllowed(); err != nil {
+		return err
+	}
+
+	if newSize > m.maxSize {
+		return NoSpaceLeft
+	}
+
+	if m.size() >= newSize {
+		return nil
+	}
+
+	return m.rehash(func(currentSize uint64) (uint64, error) {
+		if err := m.checkResizeAllowed(); err != nil {
+			return 0, err
+		}
+		if currentSize >= newSize {
+			return 0, nil
+		}
+		return newSize, nil
+	})
+}
+
+// ShrinkTo rehashes the map into a smaller storage of size newSize (rounded
+// up to a power of 2). It returns NoSpaceLeft if the keys of the map don't
+// fit into the new size. It does nothing if the storage is already not
+// bigger than newSize.
+func (m *hashTable[E]) ShrinkTo(newSize uint64) error {
+	for {
+		err := m.shrinkTo(newSize)
+		if err != AlreadyGrowing {
+			return err
+		}
+		m.concedeToGrowing()
+	}
+}
+
+func (m *hashTable[E]) shrinkTo(newSize uint64) error {
+	if err := m.checkResizeAllowed(); err != nil {
+		return err
+	}
+
+	newSize = powerOfTwoGE(newSize)
+	if m.size() <= newSize {
+		return nil
+	}
+
+	return m.rehash(func(currentSize uint64) (uint64, error) {
+		if err := m.checkResizeAllowed(); err != nil {
+			return 0, err
+		}
+		if currentSize <= newSize {
+			return 0, nil
+		}
+		if float64(m.BusySlots())/float64(newSize) >= m.growAtFullness {
+			return 0, NoSpaceLeft
+		}
+		return newSize, nil
+	})
+}
+
+// makeFreeSpace is called if there's not enough free space in the storage
+// (see isEnoughFreeSpace). It either gets rid of the tombstones of removed
+// keys or grows the storage (see mapControl.shouldDropTombstones).
+func (m *hashTable[E]) makeFreeSpace() error {
+	size := m.size()
+	if m.shouldDropTombstones(size) {
+		return m.dropTombstones()
+	}
+	newSize, err := m.grownSize(size)
+	if err != nil {
+		return err
+	}
+	return m.growTo(newSize)
+}
+
+// dropTombstones rehashes the map into a storage of the same size, so all
+// the tombstones are freed. It's allowed even if growing is forbidden: the
+// size of the storage is not changed.
+func (m *hashTable[E]) dropTombstones() error {
+	return m.rehash(func(currentSize uint64) (uint64, error) {
+		if atomic.LoadInt64(&m.removedSlots) == 0 {
+			return 0, nil
+		}
+		return currentSize, nil
+	})
+}
+
+// rehash starts moving all the keys into a new storage (the tombstones are
+// not moved). The keys are moved incrementally by the writers, see
+// migration.go. The size of the new storage is returned by getNewSize, which
+// is called again when the map is already taken exclusively (so the
+// conditions could be rechecked there); zero size means there's nothing to
+// do.
+//
+// The previous migration (if any) is finished first.
+func (m *hashTable[E]) rehash(getNewSize func(currentSize uint64) (uint64, error)) error {
+	m.finishMigration()
+
+	// Allocating the new storage before taking the map exclusively, so the
+	// writers don't wait for it.
+	newSize, err := getNewSize(m.size())
+	if newSize == 0 || err != nil {
+		return err
+	}
+	nextStorage := newStorage[E](newSize)
+
+	if m.threadSafety {
+		if !m.beginExclusive() {
+			return AlreadyGrowing
+		}
+		defer m.endExclusive()
+	}
+
+	oldStorage := m.loadStorage()
+	if oldStorage.loadNext() != nil {
+		// Somebody has already started another migration
+		return AlreadyGrowing
+	}
+	newSize, err = getNewSize(oldStorage.size())
+	if newSize == 0 || err != nil {
+		return err
+	}
+	if newSize != nextStorage.size() {
+		nextStorage = newStorage[E](newSize)
+	}
+
+	atomic.StoreInt64(&m.removedSlots, 0)
+	if m.BusySlots() == 0 {
+		// There's nothing to move
+		atomic.StorePointer((*unsafe.Pointer)((unsafe.Pointer)(&m.storage)), (unsafe.Pointer)(nextStorage))
+		return nil
+	}
+	oldStorage.storeNext(nextStorage)
+	return nil
+}
+
+// SetForbidGrowing forbids (or allows again) the storage to be resized.
+// While growing is forbidden, the writes which need more space return
+// ForbiddenToGrow (or reuse the tombstones of removed keys). A resize which
+// was refused while growing was forbidden is done when growing is allowed
+// again.
+//
+// It's safe to call it concurrently with other methods.
+func (m *hashTable[E]) SetForbidGrowing(forbidGrowing bool) {
+	m.setForbidGrowing(forbidGrowing)
+	if !forbidGrowing && m.takeDeferredResize() {
+		m.resizeIfNeeded()
+	}
+}
+
+// resizeIfNeeded grows the storage if there's not enough free space in it,
+// or shrinks it if automatic shrinking is enabled and the map is too empty.
+// The free space is checked for one more key, like a writer does (see
+// enterWrite), since the refused writer was going to add a key.
+func (m *hashTable[E]) resizeIfNeeded() {
+	if float64(m.occupiedSlots()+1)/float64(m.size()) >= m.growAtFullness {
+		m.makeFreeSpace()
+		return
+	}
+	m.shrinkIfNeeded()
+}
+
+// shrinkIfNeeded shrinks the storage if automatic shrinking is enabled (see
+// SetShrinkAtFullness) and the map is too empty. It gives up if somebody
+// else is resizing the map right now.
+func (m *hashTable[E]) shrinkIfNeeded() {
+	newSize := m.autoShrinkSize(m.size(), m.initialSize)
+	if newSize == 0 {
+		return
+	}
+	m.shrinkTo(newSize)
+}
+
+// getSlotByHashValue finds the slot of the key. If the map is thread-safe
+// then the slot is returned with a reader held on it, so the caller should
+// call slot.decreaseReaders() when the slot is read. It returns nil if there's
+// no such key. fastKeyType should be zero if the pre-hash is not full.
+//
+// If a migration is in progress then the key is looked for in the old
+// storage and then in the next one (see migration.go).
+func (m *hashTable[E]) getSlotByHashValue(fastKey uint64, fastKeyType uint8, hashValue uint64, isRightSlotFn func(*storageSlot[E]) bool) *storageSlot[E] {
+	for stor := m.loadStorage(); stor != nil; stor = stor.loadNext() {
+		slot := m.getSlotByHashValueFromStorage(stor, fastKey, fastKeyType, hashValue, isRightSlotFn)
+		if slot != nil {
+			return slot
+		}
+	}
+	return nil
+}
+
+func (m *hashTable[E]) getSlotByHashValueFromStorage(stor *storage[E], fastKey uint64, fastKeyType uint8, hashValue uint64, isRightSlotFn func(*storageSlot[E]) bool) *storageSlot[E] {
+	idxValue := stor.getIdx(hashValue)
+
+	for {
+		slot := &stor.items[idxValue]
+		idxValue++
+		if idxValue >= stor.size() {
+			idxValue = 0
+		}
+		var isSetStatus isSet
+		if m.threadSafety {
+			isSetStatus = slot.increaseReaders()
+		} else {
+			isSetStatus = slot.IsSet()
+		}
+		switch isSetStatus {
+		case isSet_notSet:
+			return nil
+		case isSet_removed, isSet_moved:
+			continue
+		case isSet_set:
+		default:
+			panic("shouldn't happened")
+		}
+
+		if slot.hashValue != hashValue {
+			if m.threadSafety {
+				slot.decreaseReaders()
+			}
+			continue
+		}
+		var isRightSlot bool
+		if slot.fastKeyType != 0 || fastKeyType != 0 {
+			isRightSlot = slot.fastKey == fastKey && slot.fastKeyType == fastKeyType
+		} else {
+			isRightSlot = isRightSlotFn(slot)
+		}
+		if !isRightSlot {
+			if m.threadSafety {
+				slot.decreaseReaders()
+			}
+			continue
+		}
+
+		return slot
+	}
+}
+
+// lockSlotByHashValue finds the slot of the key and holds it in state
+// "updating" (if the map is thread-safe). It returns nil if there's no such
+// key. The slot should be released by the caller by setting a new state.
+// typeID should be zero if the pre-hash is not full.
+func (m *hashTable[E]) lockSlotByHashValue(preHashValue uint64, typeID uint8, hashValue uint64, compareKey func(*storageSlot[E]) bool) (*storageSlot[E], uint64) {
+	m.helpMigration()
+	m.migrateKey(preHashValue, typeID, hashValue, compareKey)
+	stor := m.lastStorage()
+	idxValue := stor.getIdx(hashValue)
+
+	for {
+		slot := &stor.items[idxValue]
+		curIdxValue := idxValue
+		idxValue++
+		if idxValue >= stor.size() {
+			idxValue = 0
+		}
+		switch slot.IsSet() {
+		case isSet_notSet:
+			return nil, math.MaxUint64
+		case isSet_removed:
+			continue
+		}
+		if m.threadSafety {
+			if !slot.setIsUpdating() {
+				continue
+			}
+		}
+		if slot.hashValue != hashValue {
+			slot.isSet.Store(isSet_set)
+			continue
+		}
+
+		var isEqualKey bool
+		if slot.fastKeyType != 0 || typeID != 0 {
+			isEqualKey = slot.fastKey == preHashValue && slot.fastKeyType == typeID
+		} else {
+			isEqualKey = compareKey(slot)
+		}
+		if !isEqualKey {
+			slot.isSet.Store(isSet_set)
+			continue
+		}
+
+		return slot, curIdxValue
+	}
+}
+
+// unset removes the key of the slot locked by lockSlot (see
+// lockSlotByHashValue) if conditionFunc returns true for the slot (or if
+// conditionFunc is nil).
+func (m *hashTable[E]) unset(lockSlot func() *storageSlot[E], conditionFunc func(*storageSlot[E]) bool) error {
+	if m.BusySlots() == 0 {
+		return NotFound
+	}
+	if m.threadSafety {
+		m.enterWriteSection(1)
+		defer m.leaveWriteSection(1)
+	}
+	slot := lockSlot()
+	if slot == nil {
+		return NotFound
+	}
+	if conditionFunc != nil && !m.callLocked(slot, false, conditionFunc) {
+		slot.isSet.Store(isSet_set)
+		return ConditionFailed
+	}
+	m.removeLockedSlot(slot)
+	return nil
+}
+
+// callLocked calls a user callback (a condition, a comparison or an update
+// function) for a slot held in state "updating" (see lockSlotByHashValue),
+// or in state "setting" if isNewSlot. If fn panics, the slot is released
+// before the panic goes further (a new slot is released as a removed one),
+// otherwise the key would stay locked forever.
+func (m *hashTable[E]) callLocked(slot *storageSlot[E], isNewSlot bool, fn func(*storageSlot[E]) bool) bool {
+	isReturned := false
+	defer func() {
+		if isReturned {
+			return
+		}
+		if isNewSlot {
+			slot.clearKey()
+			atomic.AddInt64(&m.removedSlots, 1)
+			slot.isSet.Store(isSet_removed)
+			return
+		}
+		slot.isSet.Store(isSet_set)
+	}()
+	result := fn(slot)
+	isReturned = true
+	return result
+}
+
+// removeLockedSlot removes the key of a slot locked by lockSlotByHashValue.
+// The slot becomes a tombstone until the next rehash (see makeFreeSpace).
+func (m *hashTable[E]) removeLockedSlot(slot *storageSlot[E]) {
+	if m.threadSafety {
+		slot.waitForReadersOut()
+	}
+	slot.clearKey()
+	atomic.AddInt64(&m.busySlots, -1)
+	atomic.AddInt64(&m.removedSlots, 1)
+	slot.isSet.Store(isSet_removed)
+}
+
+// visitSlot calls "read" for the slot while iterating over a storage if
+// there's a key in the slot. The slot is held by a reader while "read" is
+// called. A moved slot is read as well: its entry is not changed anymore
+// (see migration.go). It returns false if there's no key in the slot.
+func (m *hashTable[E]) visitSlot(slot *storageSlot[E], read func(*storageSlot[E])) bool {
+	var isSetStatus isSet
+	if m.threadSafety {
+		isSetStatus = slot.increaseReaders()
+	} else {
+		isSetStatus = slot.IsSet()
+	}
+	switch isSetStatus {
+	case isSet_set:
+		read(slot)
+		if m.threadSafety {
+			slot.decreaseReaders()
+		}
+		return true
+	case isSet_moved:
+		read(slot)
+		return true
+	}
+	return false
+}
+
+func (m *hashTable[E]) BusySlots() uint64 {
+	return uint64(atomic.LoadInt64(&m.busySlots))
+}
+
+// Clear removes all the keys from the map (including the tombstones of
+// removed keys). The size of the storage is preserved.
+//
+// It's safe to call Clear concurrently with other methods: it waits until
+// the current writers are finished and blocks new writers until the map is
+// cleared.
+func (m *hashTable[E]) Clear() {
+	m.replaceStorage(m.size())
+}
+
+// Reset is the same as Clear, but it also shrinks the storage back to the
+// initial size of the map.
+func (m *hashTable[E]) Reset() {
+	m.replaceStorage(m.initialSize)
+}
+
+func (m *hashTable[E]) replaceStorage(newSize uint64) {
+	if m.threadSafety {
+		for !m.beginExclusive() {
+			m.concedeToGrowing()
+		}
+		defer m.endExclusive()
+	}
+
+	atomic.StorePointer((*unsafe.Pointer)((unsafe.Pointer)(&m.storage)), (unsafe.Pointer)(newStorage[E](newSize)))
+	atomic.StoreInt64(&m.busySlots, 0)
+	atomic.StoreInt64(&m.removedSlots, 0)
+}
+
+// loadStorage atomically loads the pointer to the current storage. A lookup
+// should use one loaded pointer for the whole operation (the storage could
+// be replaced meanwhile).
+func (m *hashTable[E]) loadStorage() *storage[E] {
+	return (*storage[E])(atomic.LoadPointer((*unsafe.Pointer)((unsafe.Pointer)(&m.storage))))
+}
+
+func (m *hashTable[E]) size() uint64 {
+	return m.lastStorage().size()
+}
@@ -0,0 +1,131 @@
+package hasher
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+// FloatKeyPolicy defines which float (and complex) keys are considered to be
+// the same key.
+type FloatKeyPolicy uint8
+
+const (
+	// FloatKeysGoCompatible is the semantics of the built-in maps (and of
+	// "=="): +0 and -0 are the same key, and a NaN key is not equal to
+	// anything (including itself). So every setting of a NaN key adds a new
+	// element, and a NaN key is never found. It's the default.
+	FloatKeysGoCompatible = FloatKeyPolicy(iota)
+
+	// FloatKeysBitwise compares the float keys by their bits: +0 and -0 are
+	// different keys, and a NaN key is found by a NaN with the same bits.
+	FloatKeysBitwise
+)
+
+func (policy FloatKeyPolicy) String() string {
+	switch policy {
+	case FloatKeysGoCompatible:
+		return "go-compatible"
+	case FloatKeysBitwise:
+		return "bitwise"
+	}
+	return "unknown"
+}
+
+// nanCounter is used to give different pre-hashes to NaN keys (as the
+// built-in maps do with random hashes), otherwise all of them would be
+// placed into one chain of slots.
+var nanCounter uint64
+
+func nanPreHash() uint64 {
+	return atomic.AddUint64(&nanCounter, 1) * 0x9e3779b97f4a7c15
+}
+
+// PreHashFloat32 is PreHash of a float32 key with FloatKeysGoCompatible.
+func PreHashFloat32(in float32) (uint64, uint8, bool) {
+	return preHashFloat32(in, FloatKeysGoCompatible)
+}
+
+// PreHashFloat64 is PreHash of a float64 key with FloatKeysGoCompatible.
+func PreHashFloat64(in float64) (uint64, uint8, bool) {
+	return preHashFloat64(in, FloatKeysGoCompatible)
+}
+
+// The float keys are full (compared by the pre-hash value) except NaN in
+// FloatKeysGoCompatible: it's passed to IsEqualKey, which never finds it
+// equal.
+
+func preHashFloat32(in float32, policy FloatKeyPolicy) (uint64, uint8, bool) {
+	if policy == FloatKeysBitwise {
+		return uint64(math.Float32bits(in)), 13, true
+	}
+	if in != in {
+		return nanPreHash(), 13, false
+	}
+	return floatBits(float64(in)), 13, true
+}
+
+func preHashFloat64(in float64, policy FloatKeyPolicy) (uint64, uint8, bool) {
+	if policy == FloatKeysBitwise {
+		return math.Float64bits(in), 14, true
+	}
+	if in != in {
+		return nanPreHash(), 14, false
+	}
+	return floatBits(in), 14, true
+}
+
+func preHashComplex64(in complex64, policy FloatKeyPolicy) (uint64, uint8, bool) {
+	re, im := real(in), imag(in)
+	if policy == FloatKeysBitwise {
+		return uint64(math.Float32bits(re)) | uint64(math.Float32bits(im))<<32, 18, true
+	}
+	if re != re || im != im {
+		return nanPreHash(), 18, false
+	}
+	if re == 0 {
+		re = 0
+	}
+	if im == 0 {
+		im = 0
+	}
+	return uint64(math.Float32bits(re)) | uint64(math.Float32bits(im))<<32, 18, true
+}
+
+// preHashComplex128 never returns a full key: 128 bits don't fit into the
+// pre-hash value, so the keys are compared by IsEqualKey.
+func preHashComplex128(in complex128, policy FloatKeyPolicy) (uint64, uint8, bool) {
+	re, im := real(in), imag(in)
+	if policy == FloatKeysBitwise {
+		return math.Float64bits(re) ^ bits.RotateLeft64(math.Float64bits(im), 32), 15, false
+	}
+	if re != re || im != im {
+		return nanPreHash(), 15, false
+	}
+	return floatBits(re) ^ bits.RotateLeft64(floatBits(im), 32), 15, false
+}
+
+// isEqualFloatKeyBitwise compares the float and complex keys by their bits
+// (see FloatKeysBitwise). isFloat is false if keyA is not a float or a
+// complex.
+func isEqualFloatKeyBitwise(keyA, keyB interface{}) (isEqual bool, isFloat bool) {
+	switch a := keyA.(type) {
+	case float32:
+		b, ok := keyB.(float32)
+		return ok && math.Float32bits(a) == math.Float32bits(b), true
+	case float64:
+		b, ok := keyB.(float64)
+		return ok && math.Float64bits(a) == math.Float64bits(b), true
+	case complex64:
+		b, ok := keyB.(complex64)
+		return ok &&
+			math.Float32bits(real(a)) == math.Float32bits(real(b)) &&
+			math.Float32bits(imag(a)) == math.Float32bits(imag(b)), true
+	case complex128:
+		b, ok := keyB.(complex128)
+		return ok &&
+			math.Float64bits(real(a)) == math.Float64bits(real(b)) &&
+			math.Float64bits(imag(a)) == math.Float64bits(imag(b)), true
+	}
+	return false, false
+}
@@ -0,0 +1,57 @@
+package hasher
+
+import (
+	"math"
+	"testing"
+)
+
+// TestFloatKeyPolicies checks that the pre-hash values agree with IsEqualKey:
+// the equal keys have the same pre-hash values, and the full keys with the
+// same pre-hash values are equal.
+func TestFloatKeyPolicies(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	nan := math.NaN()
+	keys := []interface{}{
+		0.0, negZero, nan, 1.0,
+		float32(0), float32(negZero), float32(nan),
+		complex(0, 0), complex(negZero, negZero), complex(nan, 0),
+		complex64(complex(0, 0)), complex64(complex(0, negZero)), complex64(complex(nan, nan)),
+	}
+	for _, h := range []interface {
+		PreHash(interface{}) (uint64, uint8, bool)
+		IsEqualKey(a, b interface{}) bool
+	}{
+		New(),
+		NewWithFloatKeyPolicy(FloatKeysBitwise),
+		NewSeeded(RandomSeed()).WithFloatKeyPolicy(FloatKeysBitwise),
+	} {
+		for _, keyA := range keys {
+			preHashA, typeIDA, isFullA := h.PreHash(keyA)
+			for _, keyB := range keys {
+				preHashB, typeIDB, isFullB := h.PreHash(keyB)
+				isEqual := h.IsEqualKey(keyA, keyB)
+				if isEqual && (preHashA != preHashB || typeIDA != typeIDB) {
+					t.Errorf("%T: %#v and %#v are equal, but the pre-hashes are different", h, keyA, keyB)
+				}
+				if isFullA && isFullB && preHashA == preHashB && typeIDA == typeIDB && !isEqual {
+					t.Errorf("%T: %#v and %#v have the same full pre-hashes, but are not equal", h, keyA, keyB)
+				}
+			}
+		}
+	}
+
+	goCompatible := New()
+	if !goCompatible.IsEqualKey(0.0, negZero) {
+		t.Errorf("+0 and -0 should be the same key")
+	}
+	if goCompatible.IsEqualKey(nan, nan) {
+		t.Errorf("NaN should not be equal to itself")
+	}
+	bitwise := NewWithFloatKeyPolicy(FloatKeysBitwise)
+	if bitwise.IsEqualKey(0.0, negZero) {
+		t.Errorf("+0 and -0 should be different keys")
+	}
+	if !bitwise.IsEqualKey(nan, nan) {
+		t.Errorf("NaN should be equal to itself")
+	}
+}
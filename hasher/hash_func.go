@@ -2,8 +2,8 @@ package hasher
 
 import (
 	"fmt"
-	"math"
 	"math/bits"
+	"reflect"
 	//"sync/atomic"
 
 	"github.com/OneOfOne/xxhash"
@@ -20,24 +20,40 @@ const (
 )
 
 func PreHashString(in string) (uint64, uint8, bool) {
-	if len(in) <= 8 {
-		v := uint64(0)
-		for i, c := range in {
-			v += uint64(c) << (uint(i) << 3)
+	return preHashString(in, 0)
+}
+func PreHashBytes(in []byte) (uint64, uint8, bool) {
+	return preHashBytes(in, 0)
+}
+
+// preHashString is PreHashString with the seed for xxhash (0 is the default
+// seed of xxhash). Short strings are not hashed here at all, the seed is
+// applied to them by CompleteHash of SeededHasher.
+//
+// A short string is stored in the pre-hash value as is: up to 7 bytes and
+// the length in the highest byte (otherwise "a" and "a\x00" would be the
+// same). The bytes are taken as is, not the runes: a rune may not fit into
+// a byte, so the non-ASCII strings would collide (and they are full keys,
+// which are compared by the pre-hash only).
+func preHashString(in string, seed uint64) (uint64, uint8, bool) {
+	if len(in) < 8 {
+		v := uint64(len(in)) << 56
+		for i := 0; i < len(in); i++ {
+			v |= uint64(in[i]) << (uint(i) << 3)
 		}
 		return v, 1, true
 	}
-	return xxhash.ChecksumString64(in), 1, false
+	return xxhash.ChecksumString64S(in, seed), 1, false
 }
-func PreHashBytes(in []byte) (uint64, uint8, bool) {
-	if len(in) <= 8 {
-		v := uint64(0)
-		for i, c := range in {
-			v += uint64(c) << (uint(i) << 3)
+func preHashBytes(in []byte, seed uint64) (uint64, uint8, bool) {
+	if len(in) < 8 {
+		v := uint64(len(in)) << 56
+		for i := 0; i < len(in); i++ {
+			v |= uint64(in[i]) << (uint(i) << 3)
 		}
 		return v, 2, true
 	}
-	return xxhash.Checksum64(in), 2, false
+	return xxhash.Checksum64S(in, seed), 2, false
 }
 func PreHashUint64(in uint64) (uint64, uint8, bool) {
 	return in, 12, true
@@ -46,16 +62,28 @@ func PreHashUintptr(in uintptr) (uint64, uint8, bool) {
 	return uint64(in), 16, true
 }
 
-func preHashPointer(in interface{}) uint64 {
-	panic("not implemented")
+// PreHash returns the pre-hash value of a key, the ID of the key type and
+// whether the pre-hash value with the type ID identify the key completely
+// (see interfaces.Hasher). The keys which are equal by IsEqualKey have the
+// same pre-hash; so slices and maps are hashed by their content.
+func PreHash(keyI I.Key) (value uint64, typeId uint8, isFull bool) {
+	return preHash(keyI, 0, keyPolicy{})
 }
 
-func PreHash(keyI I.Key) (value uint64, typeId uint8, isFull bool) {
+// preHash is PreHash with the seed for the hashing of strings (see
+// preHashString) and the key policy.
+func preHash(keyI I.Key, seed uint64, policy keyPolicy) (value uint64, typeId uint8, isFull bool) {
+	if policy.unifiedNumericKeys {
+		if value, typeID, ok := preHashNumeric(keyI, policy.floatKeys); ok {
+			return value, typeID, true
+		}
+	}
+	floatKeyPolicy := policy.floatKeys
 	switch key := keyI.(type) {
 	case string:
-		return PreHashString(key)
+		return preHashString(key, seed)
 	case []byte:
-		return PreHashBytes(key)
+		return preHashBytes(key, seed)
 	case int:
 		return uint64(key), 3, true
 	case uint:
@@ -77,17 +105,33 @@ func PreHash(keyI I.Key) (value uint64, typeId uint8, isFull bool) {
 	case uint64:
 		return PreHashUint64(key)
 	case float32:
-		return uint64(math.Float32bits(key)), 13, true
+		return preHashFloat32(key, floatKeyPolicy)
 	case float64:
-		return uint64(math.Float64bits(key)), 14, true
-	//case complex64:
-	//	return uint64(math.Float32bits(real(key)) ^ math.Float32bits(imag(key))), 15
+		return preHashFloat64(key, floatKeyPolicy)
+	case complex64:
+		return preHashComplex64(key, floatKeyPolicy)
 	case complex128:
-		return uint64(math.Float64bits(real(key)) ^ math.Float64bits(imag(key))), 15, false
+		return preHashComplex128(key, floatKeyPolicy)
 	case uintptr:
 		return uint64(key), 16, true
+	case bool:
+		if key {
+			return 1, 19, true
+		}
+		return 0, 19, true
+	case nil:
+		return 0, 20, true
+	case I.Hashable:
+		return key.Hash(), 17, false
 	default:
-		preHash, _, isFullValue := PreHashString(fmt.Sprintf("%v", key))
+		keyType := reflect.TypeOf(key)
+		if registeredKeyType := getRegisteredKeyType(keyType); registeredKeyType != nil {
+			return registeredKeyType.hash(key), registeredKeyType.typeID, false
+		}
+		if h := getTypeHasher(keyType); h != nil {
+			return combineHash(h.typeHash, h.preHash(reflect.ValueOf(key), seed)), 63, false
+		}
+		preHash, _, isFullValue := preHashString(fmt.Sprintf("%v", key), seed)
 		return preHash, 63, isFullValue
 	}
 }
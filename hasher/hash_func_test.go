@@ -80,3 +80,25 @@ func BenchmarkHash_intKeyType_blockSize1048576(b *testing.B) {
 func BenchmarkHash_stringKeyType_blockSize1048576(b *testing.B) {
 	benchmark.DoBenchmarkHash(b, New(), 1048576, "string")
 }
+
+func TestShortStringPreHash(t *testing.T) {
+	// A short string is a full key, so its pre-hash should identify it: the
+	// trailing zero bytes and the non-ASCII characters count
+	keys := []string{"", "a", "a\x00", "a\x00\x00", "\x00a", "é", "è", "日本", "本日", "\xff\xff\xff\xff\xff\xff\xff"}
+	preHashes := map[uint64]string{}
+	for _, key := range keys {
+		preHash, typeID, isFull := PreHashString(key)
+		if !isFull || typeID != 1 {
+			t.Errorf("PreHashString(%q): %v %v %v", key, preHash, typeID, isFull)
+		}
+		if otherKey, ok := preHashes[preHash]; ok {
+			t.Errorf("PreHashString(%q) == PreHashString(%q)", key, otherKey)
+		}
+		preHashes[preHash] = key
+
+		bytesPreHash, _, _ := PreHashBytes([]byte(key))
+		if bytesPreHash != preHash {
+			t.Errorf("PreHashBytes(%q) != PreHashString(%q)", key, key)
+		}
+	}
+}
@@ -1,13 +1,44 @@
 package hasher
 
-type Hasher struct{}
+type Hasher struct {
+	policy keyPolicy
+}
 
 func New() *Hasher {
 	return &Hasher{}
 }
 
+// NewWithFloatKeyPolicy returns the default hasher with the float key policy
+// (see FloatKeyPolicy).
+func NewWithFloatKeyPolicy(policy FloatKeyPolicy) *Hasher {
+	return New().WithFloatKeyPolicy(policy)
+}
+
+// WithFloatKeyPolicy returns a copy of the hasher with the float key policy
+// (see FloatKeyPolicy).
+func (h *Hasher) WithFloatKeyPolicy(policy FloatKeyPolicy) *Hasher {
+	result := *h
+	result.policy.floatKeys = policy
+	return &result
+}
+
+// WithUnifiedNumericKeys returns a copy of the hasher which hashes and
+// compares the keys of all the predeclared integer types and the integral
+// floats as one numeric key: int(5), int64(5), uint32(5) and float64(5) are
+// the same key. The non-integral floats are still distinct keys of their
+// types.
+func (h *Hasher) WithUnifiedNumericKeys() *Hasher {
+	result := *h
+	result.policy.unifiedNumericKeys = true
+	return &result
+}
+
 func (h *Hasher) PreHash(key interface{}) (uint64, uint8, bool) {
-	return PreHash(key)
+	return preHash(key, 0, h.policy)
+}
+
+func (h *Hasher) PreHashString(key string) (uint64, uint8, bool) {
+	return PreHashString(key)
 }
 
 func (h *Hasher) PreHashBytes(key []byte) (uint64, uint8, bool) {
@@ -15,12 +46,27 @@ func (h *Hasher) PreHashBytes(key []byte) (uint64, uint8, bool) {
 }
 
 func (h *Hasher) PreHashUint64(key uint64) (uint64, uint8, bool) {
+	if h.policy.unifiedNumericKeys {
+		return key, numericTypeID, true
+	}
 	return PreHashUint64(key)
 }
 
+func (h *Hasher) PreHashUintptr(key uintptr) (uint64, uint8, bool) {
+	if h.policy.unifiedNumericKeys {
+		return uint64(key), numericTypeID, true
+	}
+	return PreHashUintptr(key)
+}
+
 func (h *Hasher) CompleteHash(keyPreHash uint64, keyTypeID uint8) uint64 {
 	return CompleteHash(keyPreHash, keyTypeID)
 }
 func (h *Hasher) Hash(key interface{}) uint64 {
-	return Hash(key)
+	preHashValue, typeID, _ := h.PreHash(key)
+	return CompleteHash(preHashValue, typeID)
+}
+
+func (h *Hasher) IsEqualKey(keyA, keyB interface{}) bool {
+	return isEqualKey(keyA, keyB, h.policy)
 }
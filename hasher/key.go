@@ -7,12 +7,45 @@ import (
 	I "github.com/xaionaro-go/atomicmap/interfaces"
 )
 
+// keyPolicy defines which keys are the same key, if it differs from the
+// default (see Hasher.WithFloatKeyPolicy and Hasher.WithUnifiedNumericKeys).
+type keyPolicy struct {
+	floatKeys          FloatKeyPolicy
+	unifiedNumericKeys bool
+}
+
+// isEqualKey is IsEqualKey with the key policy.
+func isEqualKey(keyA, keyB interface{}, policy keyPolicy) bool {
+	if policy.unifiedNumericKeys {
+		valueA, isNegativeA, okA := numericKey(keyA, policy.floatKeys)
+		valueB, isNegativeB, okB := numericKey(keyB, policy.floatKeys)
+		if okA || okB {
+			return okA && okB && valueA == valueB && isNegativeA == isNegativeB
+		}
+	}
+	if policy.floatKeys == FloatKeysBitwise {
+		if isEqual, isFloat := isEqualFloatKeyBitwise(keyA, keyB); isFloat {
+			return isEqual
+		}
+	}
+	return IsEqualKey(keyA, keyB)
+}
+
+// IsEqualKey returns true if keyA and keyB are the same key. The keys are
+// compared as in the built-in maps, except of the key types which cannot be
+// the keys of the built-in maps: slices and maps are compared by their
+// content. The keys of different types are never equal.
 func IsEqualKey(keyA, keyB I.Key) bool {
-	if reflect.TypeOf(keyA).Kind() != reflect.TypeOf(keyB).Kind() {
+	// The keys of different types are different (even if the kinds are the
+	// same, like "int" and "type myInt int"), as in the built-in maps;
+	// comparing the kinds would make myInt(5) and int(5) the same key
+	if reflect.TypeOf(keyA) != reflect.TypeOf(keyB) {
 		return false
 	}
 
 	switch keyA.(type) {
+	case nil:
+		return true
 	case bool:
 		return keyA.(bool) == keyB.(bool)
 	case string:
@@ -58,39 +91,18 @@ func IsEqualKey(keyA, keyB I.Key) bool {
 		return keyA.(complex64) == keyB.(complex64)
 	case complex128:
 		return keyA.(complex128) == keyB.(complex128)
-	case *bool:
-		return keyA.(*bool) == keyB.(*bool)
-	case *string:
-		return keyA.(*string) == keyB.(*string)
-	case *int:
-		return keyA.(*int) == keyB.(*int)
-	case *uint:
-		return keyA.(*uint) == keyB.(*uint)
-	case *int8:
-		return keyA.(*int8) == keyB.(*int8)
-	case *uint8:
-		return keyA.(*uint8) == keyB.(*uint8)
-	case *int16:
-		return keyA.(*int16) == keyB.(*int16)
-	case *uint16:
-		return keyA.(*uint16) == keyB.(*uint16)
-	case *int32:
-		return keyA.(*int32) == keyB.(*int32)
-	case *uint32:
-		return keyA.(*uint32) == keyB.(*uint32)
-	case *int64:
-		return keyA.(*int64) == keyB.(*int64)
-	case *uint64:
-		return keyA.(*uint64) == keyB.(*uint64)
-	case *float32:
-		return keyA.(*float32) == keyB.(*float32)
-	case *float64:
-		return keyA.(*float64) == keyB.(*float64)
-	case *complex64:
-		return keyA.(*complex64) == keyB.(*complex64)
-	case *complex128:
-		return keyA.(*complex128) == keyB.(*complex128)
+	case I.Hashable:
+		return keyA.(I.Hashable).Equal(keyB)
 	default:
+		// The pointers (even *string and so on) are compared here as well: a
+		// pointer type may be registered with another equality
+		keyType := reflect.TypeOf(keyA)
+		if registeredKeyType := getRegisteredKeyType(keyType); registeredKeyType != nil {
+			return registeredKeyType.isEqual(keyA, keyB)
+		}
+		if h := getTypeHasher(keyType); h != nil {
+			return h.isEqual(reflect.ValueOf(keyA), reflect.ValueOf(keyB))
+		}
 		return fmt.Sprintf("%v", keyA) == fmt.Sprintf("%v", keyB)
 	}
 }
@@ -0,0 +1,85 @@
+package hasher
+
+import (
+	"math"
+)
+
+// The type IDs of the numeric keys if the numeric keys are unified (see
+// Hasher.WithUnifiedNumericKeys). The negative values have their own type
+// ID, so int64(-1) and uint64(math.MaxUint64) (which have the same bits) are
+// different keys.
+const (
+	numericTypeID         = 21
+	negativeNumericTypeID = 22
+)
+
+// numericKey returns the value of an integer key (of any of the predeclared
+// integer types) or of an integral float key in the unified form: the
+// bits of the value and if it's negative. ok is false for other keys
+// (including non-integral floats and integers which don't fit into
+// int64/uint64).
+//
+// -0 is unified with 0 only with FloatKeysGoCompatible (with
+// FloatKeysBitwise it's a different key than +0).
+func numericKey(keyI interface{}, floatKeyPolicy FloatKeyPolicy) (value uint64, isNegative bool, ok bool) {
+	switch key := keyI.(type) {
+	case int:
+		return uint64(key), key < 0, true
+	case uint:
+		return uint64(key), false, true
+	case int8:
+		return uint64(key), key < 0, true
+	case uint8:
+		return uint64(key), false, true
+	case int16:
+		return uint64(key), key < 0, true
+	case uint16:
+		return uint64(key), false, true
+	case int32:
+		return uint64(key), key < 0, true
+	case uint32:
+		return uint64(key), false, true
+	case int64:
+		return uint64(key), key < 0, true
+	case uint64:
+		return key, false, true
+	case uintptr:
+		return uint64(key), false, true
+	case float32:
+		return numericFloat(float64(key), floatKeyPolicy)
+	case float64:
+		return numericFloat(key, floatKeyPolicy)
+	}
+	return 0, false, false
+}
+
+func numericFloat(f float64, floatKeyPolicy FloatKeyPolicy) (value uint64, isNegative bool, ok bool) {
+	if f != math.Trunc(f) { // also NaN and infinities
+		return 0, false, false
+	}
+	switch {
+	case f == 0:
+		if floatKeyPolicy == FloatKeysBitwise && math.Signbit(f) {
+			return 0, false, false
+		}
+		return 0, false, true
+	case f > 0 && f < 1<<64:
+		return uint64(f), false, true
+	case f < 0 && f >= -1<<63:
+		return uint64(int64(f)), true, true
+	}
+	return 0, false, false
+}
+
+// preHashNumeric is the pre-hash of a key with the unified numeric keys. ok
+// is false if the key is not a numeric one (see numericKey).
+func preHashNumeric(key interface{}, floatKeyPolicy FloatKeyPolicy) (value uint64, typeID uint8, ok bool) {
+	value, isNegative, ok := numericKey(key, floatKeyPolicy)
+	if !ok {
+		return 0, 0, false
+	}
+	if isNegative {
+		return value, negativeNumericTypeID, true
+	}
+	return value, numericTypeID, true
+}
@@ -0,0 +1,53 @@
+package hasher
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUnifiedNumericKeys(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+	keys := []interface{}{
+		0, uint8(0), 0.0, negZero, float32(negZero),
+		5, int64(5), uint32(5), uintptr(5), float32(5), 5.0, 5.5, float32(5.5),
+		-1, int8(-1), -1.0, uint64(math.MaxUint64), float64(math.MaxUint64), float64(1 << 63),
+		math.Inf(1), math.NaN(), "5",
+	}
+	for _, h := range []interface {
+		PreHash(interface{}) (uint64, uint8, bool)
+		IsEqualKey(a, b interface{}) bool
+	}{
+		New().WithUnifiedNumericKeys(),
+		NewWithFloatKeyPolicy(FloatKeysBitwise).WithUnifiedNumericKeys(),
+		NewSeeded(RandomSeed()).WithUnifiedNumericKeys(),
+	} {
+		for _, keyA := range keys {
+			preHashA, typeIDA, isFullA := h.PreHash(keyA)
+			for _, keyB := range keys {
+				preHashB, typeIDB, isFullB := h.PreHash(keyB)
+				isEqual := h.IsEqualKey(keyA, keyB)
+				if isEqual && (preHashA != preHashB || typeIDA != typeIDB) {
+					t.Errorf("%T: %T(%v) and %T(%v) are equal, but the pre-hashes are different", h, keyA, keyA, keyB, keyB)
+				}
+				if isFullA && isFullB && preHashA == preHashB && typeIDA == typeIDB && !isEqual {
+					t.Errorf("%T: %T(%v) and %T(%v) have the same full pre-hashes, but are not equal", h, keyA, keyA, keyB, keyB)
+				}
+			}
+		}
+	}
+
+	h := New().WithUnifiedNumericKeys()
+	for _, pair := range [][2]interface{}{{5, uint32(5)}, {int64(-1), -1.0}, {uintptr(7), uint64(7)}, {0, negZero}} {
+		if !h.IsEqualKey(pair[0], pair[1]) {
+			t.Errorf("%T(%v) and %T(%v) should be the same key", pair[0], pair[0], pair[1], pair[1])
+		}
+	}
+	for _, pair := range [][2]interface{}{{int64(-1), uint64(math.MaxUint64)}, {5, 5.5}, {5.5, float32(5.5)}, {5, "5"}} {
+		if h.IsEqualKey(pair[0], pair[1]) {
+			t.Errorf("%T(%v) and %T(%v) should be different keys", pair[0], pair[0], pair[1], pair[1])
+		}
+	}
+	if NewWithFloatKeyPolicy(FloatKeysBitwise).WithUnifiedNumericKeys().IsEqualKey(0, negZero) {
+		t.Errorf("-0 should not be unified with 0 with FloatKeysBitwise")
+	}
+}
@@ -0,0 +1,218 @@
+package hasher
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"reflect"
+	"sync"
+)
+
+// typeHasher hashes and compares the values of a type which is not handled
+// by PreHash and IsEqualKey directly (structs, arrays, pointers and so on).
+// The equality is the same as of Go's "==" (so the keys are the same as in
+// the built-in maps), except slices and maps: they are compared by their
+// content.
+//
+// It's built once per type, see getTypeHasher.
+type typeHasher struct {
+	typeHash uint64 // makes the values of different types to have different hashes
+	preHash  func(v reflect.Value, seed uint64) uint64
+	isEqual  func(a, b reflect.Value) bool
+}
+
+// typeHashers is the cache of typeHasher-s: reflect.Type -> *typeHasher (nil
+// if the type is not supported).
+var typeHashers sync.Map
+
+// getTypeHasher returns the typeHasher of the type or nil if the values of
+// the type can't be hashed (functions, for example).
+func getTypeHasher(t reflect.Type) *typeHasher {
+	if h, ok := typeHashers.Load(t); ok {
+		return h.(*typeHasher)
+	}
+	h, _ := typeHashers.LoadOrStore(t, newTypeHasher(t))
+	return h.(*typeHasher)
+}
+
+func combineHash(hash, v uint64) uint64 {
+	return (bits.RotateLeft64(hash, 31) ^ v) * 0x9e3779b97f4a7c15
+}
+
+// floatBits returns the bits of the float with zero normalized (since -0 ==
+// +0). NaN is not equal to anything, so it doesn't matter how it's hashed.
+func floatBits(f float64) uint64 {
+	if f == 0 {
+		return 0
+	}
+	return math.Float64bits(f)
+}
+
+func newTypeHasher(t reflect.Type) *typeHasher {
+	h := &typeHasher{}
+	h.typeHash, _, _ = preHashString(t.String(), 0)
+
+	switch t.Kind() {
+	case reflect.Bool:
+		h.preHash = func(v reflect.Value, seed uint64) uint64 {
+			if v.Bool() {
+				return 1
+			}
+			return 0
+		}
+		h.isEqual = func(a, b reflect.Value) bool { return a.Bool() == b.Bool() }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.preHash = func(v reflect.Value, seed uint64) uint64 { return uint64(v.Int()) }
+		h.isEqual = func(a, b reflect.Value) bool { return a.Int() == b.Int() }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		h.preHash = func(v reflect.Value, seed uint64) uint64 { return v.Uint() }
+		h.isEqual = func(a, b reflect.Value) bool { return a.Uint() == b.Uint() }
+	case reflect.Float32, reflect.Float64:
+		h.preHash = func(v reflect.Value, seed uint64) uint64 { return floatBits(v.Float()) }
+		h.isEqual = func(a, b reflect.Value) bool { return a.Float() == b.Float() }
+	case reflect.Complex64, reflect.Complex128:
+		h.preHash = func(v reflect.Value, seed uint64) uint64 {
+			c := v.Complex()
+			return floatBits(real(c)) ^ bits.RotateLeft64(floatBits(imag(c)), 32)
+		}
+		h.isEqual = func(a, b reflect.Value) bool { return a.Complex() == b.Complex() }
+	case reflect.String:
+		h.preHash = func(v reflect.Value, seed uint64) uint64 {
+			preHash, _, _ := preHashString(v.String(), seed)
+			return preHash
+		}
+		h.isEqual = func(a, b reflect.Value) bool { return a.String() == b.String() }
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		h.preHash = func(v reflect.Value, seed uint64) uint64 { return uint64(v.Pointer()) }
+		h.isEqual = func(a, b reflect.Value) bool { return a.Pointer() == b.Pointer() }
+	case reflect.Array:
+		elem := getTypeHasher(t.Elem())
+		if elem == nil {
+			return nil
+		}
+		h.preHash = func(v reflect.Value, seed uint64) uint64 {
+			hash := uint64(0)
+			for i := 0; i < v.Len(); i++ {
+				hash = combineHash(hash, elem.preHash(v.Index(i), seed))
+			}
+			return hash
+		}
+		h.isEqual = func(a, b reflect.Value) bool {
+			for i := 0; i < a.Len(); i++ {
+				if !elem.isEqual(a.Index(i), b.Index(i)) {
+					return false
+				}
+			}
+			return true
+		}
+	case reflect.Struct:
+		var fieldIndexes []int
+		var fields []*typeHasher
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).Name == "_" { // blank fields are ignored by "=="
+				continue
+			}
+			field := getTypeHasher(t.Field(i).Type)
+			if field == nil {
+				return nil
+			}
+			fieldIndexes = append(fieldIndexes, i)
+			fields = append(fields, field)
+		}
+		h.preHash = func(v reflect.Value, seed uint64) uint64 {
+			hash := uint64(0)
+			for i, field := range fields {
+				hash = combineHash(hash, field.preHash(v.Field(fieldIndexes[i]), seed))
+			}
+			return hash
+		}
+		h.isEqual = func(a, b reflect.Value) bool {
+			for i, field := range fields {
+				if !field.isEqual(a.Field(fieldIndexes[i]), b.Field(fieldIndexes[i])) {
+					return false
+				}
+			}
+			return true
+		}
+	case reflect.Interface:
+		// The hashers of the dynamic types are taken on the fly (not while
+		// building this one), so recursive types are fine.
+		h.preHash = func(v reflect.Value, seed uint64) uint64 {
+			if v.IsNil() {
+				return 0
+			}
+			elem := mustGetTypeHasher(v.Elem().Type())
+			return combineHash(elem.typeHash, elem.preHash(v.Elem(), seed))
+		}
+		h.isEqual = func(a, b reflect.Value) bool {
+			if a.IsNil() || b.IsNil() {
+				return a.IsNil() == b.IsNil()
+			}
+			if a.Elem().Type() != b.Elem().Type() {
+				return false
+			}
+			return mustGetTypeHasher(a.Elem().Type()).isEqual(a.Elem(), b.Elem())
+		}
+	case reflect.Slice:
+		h.preHash = func(v reflect.Value, seed uint64) uint64 {
+			elem := mustGetTypeHasher(t.Elem())
+			hash := uint64(v.Len())
+			for i := 0; i < v.Len(); i++ {
+				hash = combineHash(hash, elem.preHash(v.Index(i), seed))
+			}
+			return hash
+		}
+		h.isEqual = func(a, b reflect.Value) bool {
+			if a.Len() != b.Len() {
+				return false
+			}
+			elem := mustGetTypeHasher(t.Elem())
+			for i := 0; i < a.Len(); i++ {
+				if !elem.isEqual(a.Index(i), b.Index(i)) {
+					return false
+				}
+			}
+			return true
+		}
+	case reflect.Map:
+		h.preHash = func(v reflect.Value, seed uint64) uint64 {
+			key := mustGetTypeHasher(t.Key())
+			value := mustGetTypeHasher(t.Elem())
+			hash := uint64(v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				// The order of the items is random, so the hashes of the items
+				// are just summed
+				hash += combineHash(key.preHash(iter.Key(), seed), value.preHash(iter.Value(), seed))
+			}
+			return hash
+		}
+		h.isEqual = func(a, b reflect.Value) bool {
+			if a.Len() != b.Len() {
+				return false
+			}
+			value := mustGetTypeHasher(t.Elem())
+			iter := a.MapRange()
+			for iter.Next() {
+				bValue := b.MapIndex(iter.Key())
+				if !bValue.IsValid() || !value.isEqual(iter.Value(), bValue) {
+					return false
+				}
+			}
+			return true
+		}
+	default:
+		return nil
+	}
+	return h
+}
+
+// mustGetTypeHasher is getTypeHasher, but it panics if the type is not
+// supported (the same way as the built-in maps do).
+func mustGetTypeHasher(t reflect.Type) *typeHasher {
+	h := getTypeHasher(t)
+	if h == nil {
+		panic(fmt.Sprintf("hash of unhashable type %v", t))
+	}
+	return h
+}
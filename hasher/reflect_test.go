@@ -0,0 +1,79 @@
+package hasher
+
+import (
+	"math"
+	"testing"
+)
+
+type testStruct struct {
+	A int
+	B string
+	c *int
+	_ int
+}
+
+type testNestedStruct struct {
+	S testStruct
+	I interface{}
+	F float64
+	A [2]bool
+}
+
+type testInt int
+
+func TestReflectKeys(t *testing.T) {
+	one, two := 1, 1
+	ch := make(chan int)
+	negativeZero := math.Copysign(0, -1)
+
+	for _, testCase := range []struct {
+		keyA, keyB interface{}
+		isEqual    bool
+	}{
+		{testStruct{A: 1, B: "b", c: &one}, testStruct{A: 1, B: "b", c: &one}, true},
+		{testStruct{A: 1, B: "b", c: &one}, testStruct{A: 1, B: "b", c: &two}, false},
+		{testStruct{A: 1, B: "a b"}, testStruct{A: 1, B: "a  b"}, false},
+		{testNestedStruct{I: 1, F: 0}, testNestedStruct{I: 1, F: negativeZero}, true},
+		{testNestedStruct{I: 1}, testNestedStruct{I: int64(1)}, false},
+		{testNestedStruct{I: testStruct{A: 1}}, testNestedStruct{I: testStruct{A: 1}}, true},
+		{testNestedStruct{I: []int{1}}, testNestedStruct{I: []int{1}}, true},
+		{testNestedStruct{F: math.NaN()}, testNestedStruct{F: math.NaN()}, false},
+		{testNestedStruct{A: [2]bool{true, false}}, testNestedStruct{A: [2]bool{true, false}}, true},
+		{testNestedStruct{A: [2]bool{true, false}}, testNestedStruct{A: [2]bool{false, true}}, false},
+		{[3]int{1, 2, 3}, [3]int{1, 2, 3}, true},
+		{&one, &one, true},
+		{&one, &two, false},
+		{&testStruct{}, &testStruct{}, false},
+		{ch, ch, true},
+		{ch, make(chan int), false},
+		{testInt(1), testInt(1), true},
+		{testInt(1), 1, false},
+		{true, true, true},
+		{true, false, false},
+		{complex64(1 + 2i), complex64(1 + 2i), true},
+		{complex64(1 + 2i), complex64(2 + 1i), false},
+		{nil, nil, true},
+		{nil, 0, false},
+		{[]uint32{1, 2}, []uint32{1, 2}, true},
+		{[]uint32{1, 2}, []uint32{2, 1}, false},
+		{map[string]int{"a": 1, "b": 2}, map[string]int{"b": 2, "a": 1}, true},
+		{map[string]int{"a": 1, "b": 2}, map[string]int{"a": 2, "b": 1}, false},
+	} {
+		if IsEqualKey(testCase.keyA, testCase.keyB) != testCase.isEqual {
+			t.Errorf("IsEqualKey(%#v, %#v) != %v", testCase.keyA, testCase.keyB, testCase.isEqual)
+		}
+		if IsEqualKey(testCase.keyB, testCase.keyA) != testCase.isEqual {
+			t.Errorf("IsEqualKey(%#v, %#v) != %v", testCase.keyB, testCase.keyA, testCase.isEqual)
+		}
+		if testCase.isEqual && Hash(testCase.keyA) != Hash(testCase.keyB) {
+			t.Errorf("The hash values of equal keys %#v and %#v are different", testCase.keyA, testCase.keyB)
+		}
+	}
+}
+
+func TestReflectKeysAllocs(t *testing.T) {
+	var key interface{} = testNestedStruct{S: testStruct{A: 1, B: "a string longer than 8 bytes"}, F: 1}
+	if allocs := testing.AllocsPerRun(100, func() { Hash(key) }); allocs != 0 {
+		t.Errorf("Hash() allocates: %v", allocs)
+	}
+}
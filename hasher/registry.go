@@ -0,0 +1,100 @@
+package hasher
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/xaionaro-go/atomicmap/errors"
+	I "github.com/xaionaro-go/atomicmap/interfaces"
+)
+
+const (
+	// firstRegisteredTypeID is the type ID of the first registered key type
+	// (see RegisterKeyType). The IDs below are used by PreHash itself.
+	firstRegisteredTypeID = 64
+)
+
+// HashFunc returns the hash value of a key of a registered type (see
+// RegisterKeyType).
+type HashFunc func(key interface{}) uint64
+
+// EqualFunc reports whether two keys of a registered type are equal (see
+// RegisterKeyType).
+type EqualFunc func(keyA, keyB interface{}) bool
+
+type registeredKeyType struct {
+	typeID  uint8
+	hash    HashFunc
+	isEqual EqualFunc
+}
+
+var (
+	// keyTypes is the registry of key types: map[reflect.Type]*registeredKeyType.
+	// It's copied on write, so the readers don't need any locks.
+	keyTypes        atomic.Pointer[map[reflect.Type]*registeredKeyType]
+	keyTypesLocker  sync.Mutex
+	nextKeyTypeID   = firstRegisteredTypeID
+	nativeKeyValues = []I.Key{"", []byte{}, int(0), uint(0), int8(0), uint8(0), int16(0), uint16(0), int32(0), uint32(0), int64(0), uint64(0), float32(0), float64(0), complex64(0), complex128(0), uintptr(0), false}
+)
+
+// RegisterKeyType makes PreHash and IsEqualKey use the functions "hash" and
+// "isEqual" for the keys of type "t" (instead of the reflection), and
+// returns the type ID assigned to the type. It's intended for the types
+// which can't implement Hashable (for example, the types of other packages).
+// The keys which are equal should have the same hash value.
+//
+// The type should be registered before its values are used as keys (for
+// example, in "init()"), otherwise the keys which are already in maps may be
+// not found. The types which are handled by PreHash natively (string, int
+// and so on) and the types implementing Hashable can't be registered. There
+// could be at most 192 registered types.
+func RegisterKeyType(t reflect.Type, hash HashFunc, isEqual EqualFunc) (uint8, error) {
+	if t == nil || hash == nil || isEqual == nil {
+		return 0, fmt.Errorf("%w: the type and the functions should not be nil", errors.CannotRegisterKeyType)
+	}
+	if t.Implements(reflect.TypeOf((*I.Hashable)(nil)).Elem()) {
+		return 0, fmt.Errorf("%w: type %v implements Hashable", errors.CannotRegisterKeyType, t)
+	}
+	for _, nativeKeyValue := range nativeKeyValues {
+		if t == reflect.TypeOf(nativeKeyValue) {
+			return 0, fmt.Errorf("%w: type %v is handled natively", errors.CannotRegisterKeyType, t)
+		}
+	}
+
+	keyTypesLocker.Lock()
+	defer keyTypesLocker.Unlock()
+
+	oldKeyTypes := loadKeyTypes()
+	if _, ok := oldKeyTypes[t]; ok {
+		return 0, fmt.Errorf("%w: type %v is already registered", errors.CannotRegisterKeyType, t)
+	}
+	if nextKeyTypeID > 255 {
+		return 0, fmt.Errorf("%w: too many registered types", errors.CannotRegisterKeyType)
+	}
+
+	newKeyTypes := make(map[reflect.Type]*registeredKeyType, len(oldKeyTypes)+1)
+	for k, v := range oldKeyTypes {
+		newKeyTypes[k] = v
+	}
+	typeID := uint8(nextKeyTypeID)
+	newKeyTypes[t] = &registeredKeyType{typeID: typeID, hash: hash, isEqual: isEqual}
+	keyTypes.Store(&newKeyTypes)
+	nextKeyTypeID++
+	return typeID, nil
+}
+
+func loadKeyTypes() map[reflect.Type]*registeredKeyType {
+	keyTypesPtr := keyTypes.Load()
+	if keyTypesPtr == nil {
+		return nil
+	}
+	return *keyTypesPtr
+}
+
+// getRegisteredKeyType returns the registered key type (see
+// RegisterKeyType) or nil if the type is not registered.
+func getRegisteredKeyType(t reflect.Type) *registeredKeyType {
+	return loadKeyTypes()[t]
+}
@@ -0,0 +1,98 @@
+package hasher
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	atomicmapErrors "github.com/xaionaro-go/atomicmap/errors"
+	I "github.com/xaionaro-go/atomicmap/interfaces"
+)
+
+// caseInsensitiveKey imitates a type of another package: it can't implement
+// Hashable and it can't be hashed by reflection (because of the function).
+type caseInsensitiveKey struct {
+	S        string
+	callback func()
+}
+
+var caseInsensitiveKeyTypeID, caseInsensitiveKeyTypeErr = RegisterKeyType(
+	reflect.TypeOf(caseInsensitiveKey{}),
+	func(key interface{}) uint64 {
+		preHash, _, _ := PreHashString(strings.ToLower(key.(caseInsensitiveKey).S))
+		return preHash
+	},
+	func(keyA, keyB interface{}) bool {
+		return strings.EqualFold(keyA.(caseInsensitiveKey).S, keyB.(caseInsensitiveKey).S)
+	},
+)
+
+func TestRegisterKeyType(t *testing.T) {
+	if caseInsensitiveKeyTypeErr != nil {
+		t.Fatalf("Cannot register the key type: %v", caseInsensitiveKeyTypeErr)
+	}
+	if caseInsensitiveKeyTypeID < 64 {
+		t.Errorf("The type ID is reserved by PreHash: %v", caseInsensitiveKeyTypeID)
+	}
+
+	keyA := caseInsensitiveKey{S: "Key"}
+	keyB := caseInsensitiveKey{S: "kEY"}
+	if _, typeID, _ := PreHash(keyA); typeID != caseInsensitiveKeyTypeID {
+		t.Errorf("Unexpected type ID: %v != %v", typeID, caseInsensitiveKeyTypeID)
+	}
+	if !IsEqualKey(keyA, keyB) || Hash(keyA) != Hash(keyB) {
+		t.Errorf("The registered functions are not used")
+	}
+	if IsEqualKey(keyA, caseInsensitiveKey{S: "other"}) {
+		t.Errorf("The keys are expected to be different")
+	}
+
+	for _, keyType := range []reflect.Type{
+		nil,
+		reflect.TypeOf(caseInsensitiveKey{}),
+		reflect.TypeOf(""),
+		reflect.TypeOf(int64(0)),
+		reflect.TypeOf(testHashableKey(0)),
+	} {
+		_, err := RegisterKeyType(keyType, func(interface{}) uint64 { return 0 }, func(interface{}, interface{}) bool { return false })
+		if !errors.Is(err, atomicmapErrors.CannotRegisterKeyType) {
+			t.Errorf(`An expected "CannotRegisterKeyType" error for %v, but got: %v`, keyType, err)
+		}
+	}
+}
+
+type testHashableKey int
+
+func (k testHashableKey) Hash() uint64           { return uint64(k) }
+func (k testHashableKey) Equal(other I.Key) bool { return k == other }
+
+// stringPtrTypeID makes *string keys to be compared by the strings they point
+// to. No other test of the package uses *string keys.
+var stringPtrTypeID, stringPtrTypeErr = RegisterKeyType(
+	reflect.TypeOf((*string)(nil)),
+	func(key interface{}) uint64 {
+		preHash, _, _ := PreHashString(*key.(*string))
+		return preHash
+	},
+	func(keyA, keyB interface{}) bool {
+		return *keyA.(*string) == *keyB.(*string)
+	},
+)
+
+func TestRegisterBuiltinPointerKeyType(t *testing.T) {
+	if stringPtrTypeErr != nil {
+		t.Fatalf("Cannot register the key type: %v", stringPtrTypeErr)
+	}
+
+	strA, strB, strC := "key", "key", "other"
+	if _, typeID, _ := PreHash(&strA); typeID != stringPtrTypeID {
+		t.Errorf("Unexpected type ID: %v != %v", typeID, stringPtrTypeID)
+	}
+	if !IsEqualKey(&strA, &strB) || Hash(&strA) != Hash(&strB) {
+		t.Errorf("The registered functions are not used")
+	}
+	if IsEqualKey(&strA, &strC) {
+		t.Errorf("The keys are expected to be different")
+	}
+}
@@ -0,0 +1,124 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math/bits"
+	"sync"
+)
+
+var (
+	processSeed     uint64
+	processSeedOnce sync.Once
+)
+
+// RandomSeed returns a new random seed (see NewSeeded).
+func RandomSeed() uint64 {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		panic(err)
+	}
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+// ProcessSeed returns the random seed of the process: it's drawn once (on
+// the first call) and is the same for all the following calls.
+func ProcessSeed() uint64 {
+	processSeedOnce.Do(func() {
+		processSeed = RandomSeed()
+	})
+	return processSeed
+}
+
+// SeededHasher is a Hasher which folds a seed into the hash values, so the
+// keys colliding for one seed don't collide for another one. It's intended
+// to resist hash flooding (when the keys are controlled by an attacker):
+// the seed should be random (see RandomSeed and ProcessSeed) and secret.
+//
+// It's slower than the default hasher, since CompleteHash has to mix the
+// bits of the key thoroughly.
+type SeededHasher struct {
+	seed   uint64
+	policy keyPolicy
+}
+
+func NewSeeded(seed uint64) *SeededHasher {
+	return &SeededHasher{seed: seed}
+}
+
+// WithFloatKeyPolicy returns a copy of the hasher with the float key policy
+// (see FloatKeyPolicy).
+func (h *SeededHasher) WithFloatKeyPolicy(policy FloatKeyPolicy) *SeededHasher {
+	result := *h
+	result.policy.floatKeys = policy
+	return &result
+}
+
+// WithUnifiedNumericKeys returns a copy of the hasher with the unified
+// numeric keys (see Hasher.WithUnifiedNumericKeys).
+func (h *SeededHasher) WithUnifiedNumericKeys() *SeededHasher {
+	result := *h
+	result.policy.unifiedNumericKeys = true
+	return &result
+}
+
+func (h *SeededHasher) PreHash(key interface{}) (uint64, uint8, bool) {
+	return preHash(key, h.seed, h.policy)
+}
+
+func (h *SeededHasher) PreHashString(key string) (uint64, uint8, bool) {
+	return preHashString(key, h.seed)
+}
+
+func (h *SeededHasher) PreHashBytes(key []byte) (uint64, uint8, bool) {
+	return preHashBytes(key, h.seed)
+}
+
+func (h *SeededHasher) PreHashUint64(key uint64) (uint64, uint8, bool) {
+	if h.policy.unifiedNumericKeys {
+		return key, numericTypeID, true
+	}
+	return PreHashUint64(key)
+}
+
+func (h *SeededHasher) PreHashUintptr(key uintptr) (uint64, uint8, bool) {
+	if h.policy.unifiedNumericKeys {
+		return uint64(key), numericTypeID, true
+	}
+	return PreHashUintptr(key)
+}
+
+func (h *SeededHasher) CompleteHash(keyPreHash uint64, keyTypeID uint8) uint64 {
+	return SeededCompleteHash(keyPreHash, keyTypeID, h.seed)
+}
+
+func (h *SeededHasher) Hash(key interface{}) uint64 {
+	preHashValue, typeID, _ := h.PreHash(key)
+	return h.CompleteHash(preHashValue, typeID)
+}
+
+func (h *SeededHasher) IsEqualKey(keyA, keyB interface{}) bool {
+	return isEqualKey(keyA, keyB, h.policy)
+}
+
+// SeededCompleteHash is CompleteHash with the seed. Unlike Uint64Hash (which
+// folds the key into 32 bits first), every bit of the result depends on
+// every bit of the key and of the seed, so the colliding keys can't be
+// chosen without knowing the seed.
+func SeededCompleteHash(keyPreHash uint64, keyTypeID uint8, seed uint64) uint64 {
+	hash := keyPreHash ^ bits.RotateLeft64(seed, int(keyTypeID))
+	hash = mix64(hash)
+	hash ^= seed
+	return mix64(hash)
+}
+
+// mix64 is the finalizer of MurmurHash3: a bijection with a good avalanche
+// effect.
+func mix64(v uint64) uint64 {
+	v ^= v >> 33
+	v *= 0xff51afd7ed558ccd
+	v ^= v >> 33
+	v *= 0xc4ceb9fe1a85ec53
+	v ^= v >> 33
+	return v
+}
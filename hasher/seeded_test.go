@@ -0,0 +1,52 @@
+package hasher
+
+import (
+	"testing"
+
+	benchmark "github.com/xaionaro-go/atomicmap/internal/benchmarkRoutines"
+)
+
+func TestSeededHashCollisions_blockSize1024_keyAmount800(t *testing.T) {
+	benchmark.DoTestHashCollisions(t, NewSeeded(RandomSeed()), 1024, 800)
+}
+
+func TestSeededHashDependsOnSeed(t *testing.T) {
+	h0 := NewSeeded(1)
+	h1 := NewSeeded(2)
+	for _, key := range []interface{}{0, uint64(1), "short", "a string longer than 8 bytes", []byte("a slice longer than 8 bytes"), struct{ A int }{1}} {
+		if h0.Hash(key) == h1.Hash(key) {
+			t.Errorf("The hash value of %v doesn't depend on the seed", key)
+		}
+		if h0.Hash(key) != NewSeeded(1).Hash(key) {
+			t.Errorf("The hash value of %v is not stable", key)
+		}
+	}
+
+	if ProcessSeed() != ProcessSeed() {
+		t.Errorf("The process seed is not stable")
+	}
+}
+
+// TestSeededHashFlooding checks the keys which all collide with the default
+// hasher (the upper and lower halves of each key are the same, so they are
+// folded to the same value by Uint64Hash).
+func TestSeededHashFlooding(t *testing.T) {
+	const blockSize = 1024
+	const keyAmount = 512
+
+	defaultIdxs := map[uint64]bool{}
+	seededIdxs := map[uint64]bool{}
+	h := NewSeeded(RandomSeed())
+	for i := uint64(0); i < keyAmount; i++ {
+		key := i<<32 | i
+		defaultIdxs[Hash(key)%blockSize] = true
+		seededIdxs[h.Hash(key)%blockSize] = true
+	}
+	if len(defaultIdxs) != 1 {
+		t.Logf("The keys don't collide with the default hasher anymore: %v", len(defaultIdxs))
+	}
+	// ~400 is expected for random hash values
+	if len(seededIdxs) < 300 {
+		t.Errorf("Too many collisions with the seeded hasher: only %v different indexes of %v keys", len(seededIdxs), keyAmount)
+	}
+}
@@ -0,0 +1,215 @@
+package hasher
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/xaionaro-go/atomicmap/errors"
+)
+
+// strictKeyKind is the key type of a StrictHasher.
+type strictKeyKind uint8
+
+const (
+	strictKeyString = strictKeyKind(iota)
+	strictKeyBytes
+	strictKeyInt
+	strictKeyUint
+	strictKeyInt8
+	strictKeyUint8
+	strictKeyInt16
+	strictKeyUint16
+	strictKeyInt32
+	strictKeyUint32
+	strictKeyInt64
+	strictKeyUint64
+	strictKeyUintptr
+)
+
+var strictKeyKinds = map[reflect.Type]strictKeyKind{
+	reflect.TypeOf(""):          strictKeyString,
+	reflect.TypeOf([]byte(nil)): strictKeyBytes,
+	reflect.TypeOf(int(0)):      strictKeyInt,
+	reflect.TypeOf(uint(0)):     strictKeyUint,
+	reflect.TypeOf(int8(0)):     strictKeyInt8,
+	reflect.TypeOf(uint8(0)):    strictKeyUint8,
+	reflect.TypeOf(int16(0)):    strictKeyInt16,
+	reflect.TypeOf(uint16(0)):   strictKeyUint16,
+	reflect.TypeOf(int32(0)):    strictKeyInt32,
+	reflect.TypeOf(uint32(0)):   strictKeyUint32,
+	reflect.TypeOf(int64(0)):    strictKeyInt64,
+	reflect.TypeOf(uint64(0)):   strictKeyUint64,
+	reflect.TypeOf(uintptr(0)):  strictKeyUintptr,
+}
+
+// StrictHasher is a Hasher for the keys of one type (see NewStrict). The
+// keys of the type are hashed and compared by the code specialized for the
+// type: without the type switch of PreHash and the reflection of IsEqualKey.
+// The pre-hash values and the type IDs are the same as of PreHash.
+//
+// The keys of other types are still handled (by PreHash and IsEqualKey), so
+// it never panics, but the map should reject them (see PreHashKey).
+type StrictHasher struct {
+	keyType reflect.Type
+	kind    strictKeyKind
+	seed    uint64
+	seeded  bool
+}
+
+// NewStrict returns a StrictHasher for the keys of type keyType. The
+// supported types are string, []byte, the predeclared integer types and
+// uintptr; NotImplemented is returned for other types.
+func NewStrict(keyType reflect.Type) (*StrictHasher, error) {
+	kind, ok := strictKeyKinds[keyType]
+	if !ok {
+		return nil, fmt.Errorf("%w: the strict hashing of the keys of type %v", errors.NotImplemented, keyType)
+	}
+	return &StrictHasher{keyType: keyType, kind: kind}, nil
+}
+
+// NewSeededStrict is NewStrict, but the keys are hashed with the seed (see
+// SeededHasher).
+func NewSeededStrict(keyType reflect.Type, seed uint64) (*StrictHasher, error) {
+	h, err := NewStrict(keyType)
+	if err != nil {
+		return nil, err
+	}
+	h.seed, h.seeded = seed, true
+	return h, nil
+}
+
+// Strict returns a StrictHasher for the keys of type keyType with the same
+// seed (see NewSeededStrict).
+func (h *SeededHasher) Strict(keyType reflect.Type) (*StrictHasher, error) {
+	return NewSeededStrict(keyType, h.seed)
+}
+
+// KeyType returns the type of the keys the hasher is specialized for.
+func (h *StrictHasher) KeyType() reflect.Type {
+	return h.keyType
+}
+
+// IsKeyOfType reports whether the key is of the type the hasher is
+// specialized for.
+func (h *StrictHasher) IsKeyOfType(key interface{}) bool {
+	return reflect.TypeOf(key) == h.keyType
+}
+
+// PreHashKey is PreHash for the keys of the type the hasher is specialized
+// for. "ok" is false if the key is of another type.
+func (h *StrictHasher) PreHashKey(keyI interface{}) (value uint64, typeID uint8, isFull bool, ok bool) {
+	switch h.kind {
+	case strictKeyString:
+		if key, ok := keyI.(string); ok {
+			value, typeID, isFull = preHashString(key, h.seed)
+			return value, typeID, isFull, true
+		}
+	case strictKeyBytes:
+		if key, ok := keyI.([]byte); ok {
+			value, typeID, isFull = preHashBytes(key, h.seed)
+			return value, typeID, isFull, true
+		}
+	case strictKeyInt:
+		if key, ok := keyI.(int); ok {
+			return uint64(key), 3, true, true
+		}
+	case strictKeyUint:
+		if key, ok := keyI.(uint); ok {
+			return uint64(key), 4, true, true
+		}
+	case strictKeyInt8:
+		if key, ok := keyI.(int8); ok {
+			return uint64(key), 5, true, true
+		}
+	case strictKeyUint8:
+		if key, ok := keyI.(uint8); ok {
+			return uint64(key), 6, true, true
+		}
+	case strictKeyInt16:
+		if key, ok := keyI.(int16); ok {
+			return uint64(key), 7, true, true
+		}
+	case strictKeyUint16:
+		if key, ok := keyI.(uint16); ok {
+			return uint64(key), 8, true, true
+		}
+	case strictKeyInt32:
+		if key, ok := keyI.(int32); ok {
+			return uint64(key), 9, true, true
+		}
+	case strictKeyUint32:
+		if key, ok := keyI.(uint32); ok {
+			return uint64(key), 10, true, true
+		}
+	case strictKeyInt64:
+		if key, ok := keyI.(int64); ok {
+			return uint64(key), 11, true, true
+		}
+	case strictKeyUint64:
+		if key, ok := keyI.(uint64); ok {
+			return key, 12, true, true
+		}
+	case strictKeyUintptr:
+		if key, ok := keyI.(uintptr); ok {
+			return uint64(key), 16, true, true
+		}
+	}
+	return 0, 0, false, false
+}
+
+func (h *StrictHasher) PreHash(key interface{}) (uint64, uint8, bool) {
+	if value, typeID, isFull, ok := h.PreHashKey(key); ok {
+		return value, typeID, isFull
+	}
+	return preHash(key, h.seed, keyPolicy{})
+}
+
+func (h *StrictHasher) PreHashString(key string) (uint64, uint8, bool) {
+	return preHashString(key, h.seed)
+}
+
+func (h *StrictHasher) PreHashBytes(key []byte) (uint64, uint8, bool) {
+	return preHashBytes(key, h.seed)
+}
+
+func (h *StrictHasher) PreHashUint64(key uint64) (uint64, uint8, bool) {
+	return PreHashUint64(key)
+}
+
+func (h *StrictHasher) PreHashUintptr(key uintptr) (uint64, uint8, bool) {
+	return PreHashUintptr(key)
+}
+
+func (h *StrictHasher) CompleteHash(keyPreHash uint64, keyTypeID uint8) uint64 {
+	if h.seeded {
+		return SeededCompleteHash(keyPreHash, keyTypeID, h.seed)
+	}
+	return CompleteHash(keyPreHash, keyTypeID)
+}
+
+func (h *StrictHasher) Hash(key interface{}) uint64 {
+	preHashValue, typeID, _ := h.PreHash(key)
+	return h.CompleteHash(preHashValue, typeID)
+}
+
+// IsEqualKey compares the keys. Only the string and []byte keys are
+// compared here in fact: the keys of the other supported types are full
+// (see Hasher), so the map compares them by the pre-hash values.
+func (h *StrictHasher) IsEqualKey(keyA, keyB interface{}) bool {
+	switch h.kind {
+	case strictKeyString:
+		a, okA := keyA.(string)
+		b, okB := keyB.(string)
+		if okA && okB {
+			return a == b
+		}
+	case strictKeyBytes:
+		a, okA := keyA.([]byte)
+		b, okB := keyB.([]byte)
+		if okA && okB {
+			return bytes.Equal(a, b)
+		}
+	}
+	return IsEqualKey(keyA, keyB)
+}
@@ -0,0 +1,55 @@
+package hasher
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	atomicmapErrors "github.com/xaionaro-go/atomicmap/errors"
+)
+
+// TestStrictHasher checks that StrictHasher hashes the keys in the same way
+// as the generic hashers (so the methods like GetByUint64 agree with it).
+func TestStrictHasher(t *testing.T) {
+	keys := []interface{}{
+		"", "short", "a string longer than 8 bytes", []byte("bytes"), []byte("bytes longer than 8 bytes"),
+		-1, uint(1), int8(-2), uint8(2), int16(-3), uint16(3), int32(-4), uint32(4), int64(-5), uint64(5), uintptr(6),
+	}
+	seed := RandomSeed()
+	for _, key := range keys {
+		keyType := reflect.TypeOf(key)
+		strict, err := NewStrict(keyType)
+		if err != nil {
+			t.Fatalf("NewStrict(%v): %v", keyType, err)
+		}
+		seededStrict, err := NewSeeded(seed).Strict(keyType)
+		if err != nil {
+			t.Fatalf("NewSeeded().Strict(%v): %v", keyType, err)
+		}
+		if strict.Hash(key) != Hash(key) {
+			t.Errorf("%T(%v): the strict hash value differs from the default one", key, key)
+		}
+		if seededStrict.Hash(key) != NewSeeded(seed).Hash(key) {
+			t.Errorf("%T(%v): the seeded strict hash value differs from the seeded one", key, key)
+		}
+		if !strict.IsKeyOfType(key) {
+			t.Errorf("%T(%v) should be of the type of the hasher", key, key)
+		}
+		for _, otherKey := range keys {
+			if strict.IsEqualKey(key, otherKey) != IsEqualKey(key, otherKey) {
+				t.Errorf("%T(%v) and %T(%v): the strict IsEqualKey differs from the default one", key, key, otherKey, otherKey)
+			}
+			if reflect.TypeOf(otherKey) != keyType && strict.IsKeyOfType(otherKey) {
+				t.Errorf("%T(%v) should not be of type %v", otherKey, otherKey, keyType)
+			}
+			// the keys of other types are still hashed as by PreHash
+			if strict.Hash(otherKey) != Hash(otherKey) {
+				t.Errorf("%T(%v): the strict hash value of a key of another type differs from the default one", otherKey, otherKey)
+			}
+		}
+	}
+
+	if _, err := NewStrict(reflect.TypeOf(struct{}{})); !errors.Is(err, atomicmapErrors.NotImplemented) {
+		t.Errorf("NewStrict(struct{}) should return NotImplemented, got %v", err)
+	}
+}
@@ -5,4 +5,5 @@ import (
 )
 
 type Key = I.Key
+type Hashable = I.Hashable
 type iMap = I.Map
@@ -2,14 +2,42 @@ package interfaces
 
 type Key interface{}
 
+// Hashable is implemented by the user-defined key types which define their
+// own hashing and equality (instead of the formatting with fmt.Sprintf).
+// Equal should return false if "other" has another type; and the keys which
+// are equal should have the same Hash().
+type Hashable interface {
+	Hash() uint64
+	Equal(other Key) bool
+}
+
 type Map interface {
 	Set(key Key, value interface{}) error
 	SetBytesByBytes(key []byte, value []byte) error
 	Swap(key Key, value interface{}) (interface{}, error)
 	Get(key Key) (value interface{}, err error)
+	Unset(key Key) error
+
+	// The same as above, but for the keys of a known type (without passing
+	// them as interface{})
+
+	SetByString(key string, value interface{}) error
+	GetByString(key string) (value interface{}, err error)
+	SwapByString(key string, value interface{}) (interface{}, error)
+	UnsetByString(key string) error
+	SetByBytes(key []byte, value interface{}) error
 	GetByBytes(key []byte) (value interface{}, err error)
+	SwapByBytes(key []byte, value interface{}) (interface{}, error)
+	UnsetByBytes(key []byte) error
+	SetByUint64(key uint64, value interface{}) error
 	GetByUint64(key uint64) (value interface{}, err error)
-	Unset(key Key) error
+	SwapByUint64(key uint64, value interface{}) (interface{}, error)
+	UnsetByUint64(key uint64) error
+	SetByUintptr(key uintptr, value interface{}) error
+	GetByUintptr(key uintptr) (value interface{}, err error)
+	SwapByUintptr(key uintptr, value interface{}) (interface{}, error)
+	UnsetByUintptr(key uintptr) error
+
 	Len() int
 	Keys() []interface{}
 	ToSTDMap() map[Key]interface{}
@@ -17,10 +45,17 @@ type Map interface {
 	SetForbidGrowing(bool)
 }
 
+// Hasher calculates the hash values of keys. The PreHash* methods return the
+// pre-hash value, the ID of the key type and a flag "isFull". "isFull" should
+// be true only if the pre-hash value and the type ID identify the key
+// completely (then the keys are compared by them, without IsEqualKey).
 type Hasher interface {
 	PreHash(key interface{}) (uint64, uint8, bool)
+	PreHashString(key string) (uint64, uint8, bool)
 	PreHashBytes(key []byte) (uint64, uint8, bool)
 	PreHashUint64(key uint64) (uint64, uint8, bool)
+	PreHashUintptr(key uintptr) (uint64, uint8, bool)
 	CompleteHash(preHash uint64, typeID uint8) uint64
 	Hash(key interface{}) uint64
+	IsEqualKey(keyA, keyB interface{}) bool
 }
@@ -11,8 +11,12 @@ var (
 	benchmarkActionNames = []string{"Set" /*"ReSet", */, "Get" /*"GetMiss", */, "Unset" /*"UnsetMiss"*/}
 	blockSizes           = []int{16, 64, 128, 1024, 65536, 4 * 1024 * 1024, 16 * 1024 * 1024}
 	keyAmounts           = []int{16, 512, 65536, 1024 * 1024}
-	keyTypes             = []string{"int", "string" /*"slice", "map", "struct"*/}
+	keyTypes             = []string{"int", "string", "slice", "map", "struct"}
 	threadSafeties       = []bool{true}
+
+	// the benchmarks of the methods like GetByString (only for atomicmap)
+	typedKeyActionNames = []string{"Set", "Get", "Swap", "Unset"}
+	typedKeyTypes       = []string{"String", "Bytes", "Uint64", "Uintptr"}
 )
 
 type hashMapSourceFile struct {
@@ -83,10 +87,21 @@ func (file hashMapSourceFile) GenerateTestFile() error {
 		if err != nil {
 			return err
 		}
+	}
+	switch file.PackageName {
+	case "openAddressGrowingMap", "atomicmap":
 		err = tpl.ExecuteTemplate(outFileWriter, "testConcurrencyFunction", data)
 		if err != nil {
 			return err
 		}
+		err = tpl.ExecuteTemplate(outFileWriter, "testConcurrencyWithUnsetFunction", data)
+		if err != nil {
+			return err
+		}
+		err = tpl.ExecuteTemplate(outFileWriter, "testKeyTypesFunction", data)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Write the benchmark functions
@@ -134,5 +149,28 @@ func (file hashMapSourceFile) GenerateTestFile() error {
 		}
 	}
 
+	if file.PackageName != "atomicmap" {
+		return nil
+	}
+	for _, actionName := range typedKeyActionNames {
+		data["Action"] = actionName
+		for _, blockSize := range blockSizesFixed {
+			data["BlockSize"] = blockSize
+			for _, keyAmount := range keyAmounts {
+				if keyAmount*8 > blockSize || keyAmount*1024 < blockSize {
+					continue
+				}
+				data["KeyAmount"] = keyAmount
+				for _, keyType := range typedKeyTypes {
+					data["KeyType"] = keyType
+					err = tpl.ExecuteTemplate(outFileWriter, "typedKeyBenchmarkFunction", data)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
 	return nil
 }
@@ -14,16 +14,21 @@ import (
 
 {{ define "benchmarkFunction" }}
 func Benchmark_{{ .PackageName }}_{{ .Action }}_{{ .KeyType }}KeyType_blockSize{{ .BlockSize }}_keyAmount{{ .KeyAmount }}_{{ .ThreadSafety }}ThreadSafety(b *testing.B) {
-	{{if and (not .ThreadSafety) (eq .PackageName "openAddressGrowingMap")}}threadSafe = false; {{end}}benchmark.DoBenchmarkOf{{ .Action }}(b, newWithArgsIface, {{ .BlockSize }}, {{ .KeyAmount }}, "{{ .KeyType }}"){{if and (not .ThreadSafety) (eq .PackageName "openAddressGrowingMap")}}; threadSafe = true{{end}}
+	benchmark.DoBenchmarkOf{{ .Action }}(b, {{if and (not .ThreadSafety) (eq .PackageName "atomicmap")}}newThreadUnsafeWithArgsIface{{else}}newWithArgsIface{{end}}, {{ .BlockSize }}, {{ .KeyAmount }}, "{{ .KeyType }}")
 }
 {{ if .ThreadSafety }}
 {{ if ne .Action "Unset" }}
 func BenchmarkParallel_{{ .PackageName }}_{{ .Action }}_{{ .KeyType }}KeyType_blockSize{{ .BlockSize }}_keyAmount{{ .KeyAmount }}_{{ .ThreadSafety }}ThreadSafety(b *testing.B) {
-	{{if and (not .ThreadSafety) (eq .PackageName "openAddressGrowingMap")}}threadSafe = false; {{end}}benchmark.DoParallelBenchmarkOf{{ .Action }}(b, newWithArgsIface, {{ .BlockSize }}, {{ .KeyAmount }}, "{{ .KeyType }}"){{if and (not .ThreadSafety) (eq .PackageName "openAddressGrowingMap")}}; threadSafe = true{{end}}
+	benchmark.DoParallelBenchmarkOf{{ .Action }}(b, newWithArgsIface, {{ .BlockSize }}, {{ .KeyAmount }}, "{{ .KeyType }}")
 }
 {{ end }}
 {{ end }}
 {{ end }}
+{{ define "typedKeyBenchmarkFunction" }}
+func Benchmark_{{ .PackageName }}_{{ .Action }}By{{ .KeyType }}_blockSize{{ .BlockSize }}_keyAmount{{ .KeyAmount }}(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, {{ .BlockSize }}, {{ .KeyAmount }}, "{{ .Action }}", "{{ .KeyType }}")
+}
+{{ end }}
 {{ define "testFunction" }}
 func TestMap(t *testing.T) {
 	benchmark.DoTest(t, newWithArgsIface)
@@ -39,5 +44,15 @@ func TestMapConcurrency(t *testing.T) {
 	benchmark.DoTestConcurrency(t, newWithArgsIface)
 }
 {{ end }}
+{{ define "testKeyTypesFunction" }}
+func TestMapKeyTypes(t *testing.T) {
+	benchmark.DoTestKeyTypes(t, newWithArgsIface)
+}
+{{ end }}
+{{ define "testConcurrencyWithUnsetFunction" }}
+func TestMapConcurrencyWithUnset(t *testing.T) {
+	benchmark.DoTestConcurrencyWithUnset(t, newWithArgsIface)
+}
+{{ end }}
 `
 )
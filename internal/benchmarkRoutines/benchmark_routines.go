@@ -1,6 +1,7 @@
 package benchmarkRoutines
 
 import (
+	"encoding/binary"
 	"testing"
 
 	I "github.com/xaionaro-go/atomicmap/interfaces"
@@ -298,3 +299,116 @@ func DoParallelBenchmarkOfUnsetMiss(b *testing.B, factoryFunc mapFactoryFunc, bl
 	})
 	b.StopTimer()
 }
+
+// typedKeys are the keys generated by generateKeys converted to the key types
+// of the methods like GetByString (see typedKeys.method).
+type typedKeys struct {
+	strings  []string
+	bytes    [][]byte
+	uint64s  []uint64
+	uintptrs []uintptr
+}
+
+func generateTypedKeys(keyAmount uint64) *typedKeys {
+	keys := &typedKeys{}
+	for _, key := range generateKeys(keyAmount, "string") {
+		keyString := key.(string)
+		keyUint32 := binary.LittleEndian.Uint32([]byte(keyString))
+		keys.strings = append(keys.strings, keyString)
+		keys.bytes = append(keys.bytes, []byte(keyString))
+		keys.uint64s = append(keys.uint64s, uint64(keyUint32))
+		keys.uintptrs = append(keys.uintptrs, uintptr(keyUint32))
+	}
+	return keys
+}
+
+// method returns a function calling the method (like "GetByString")
+// of the map for the idx-th key.
+func (keys *typedKeys) method(methodName string) func(m I.Map, idx uint64, value interface{}) {
+	switch methodName {
+	case "SetByString":
+		return func(m I.Map, idx uint64, value interface{}) { m.SetByString(keys.strings[idx], value) }
+	case "GetByString":
+		return func(m I.Map, idx uint64, value interface{}) { m.GetByString(keys.strings[idx]) }
+	case "SwapByString":
+		return func(m I.Map, idx uint64, value interface{}) { m.SwapByString(keys.strings[idx], value) }
+	case "UnsetByString":
+		return func(m I.Map, idx uint64, value interface{}) { m.UnsetByString(keys.strings[idx]) }
+	case "SetByBytes":
+		return func(m I.Map, idx uint64, value interface{}) { m.SetByBytes(keys.bytes[idx], value) }
+	case "GetByBytes":
+		return func(m I.Map, idx uint64, value interface{}) { m.GetByBytes(keys.bytes[idx]) }
+	case "SwapByBytes":
+		return func(m I.Map, idx uint64, value interface{}) { m.SwapByBytes(keys.bytes[idx], value) }
+	case "UnsetByBytes":
+		return func(m I.Map, idx uint64, value interface{}) { m.UnsetByBytes(keys.bytes[idx]) }
+	case "SetByUint64":
+		return func(m I.Map, idx uint64, value interface{}) { m.SetByUint64(keys.uint64s[idx], value) }
+	case "GetByUint64":
+		return func(m I.Map, idx uint64, value interface{}) { m.GetByUint64(keys.uint64s[idx]) }
+	case "SwapByUint64":
+		return func(m I.Map, idx uint64, value interface{}) { m.SwapByUint64(keys.uint64s[idx], value) }
+	case "UnsetByUint64":
+		return func(m I.Map, idx uint64, value interface{}) { m.UnsetByUint64(keys.uint64s[idx]) }
+	case "SetByUintptr":
+		return func(m I.Map, idx uint64, value interface{}) { m.SetByUintptr(keys.uintptrs[idx], value) }
+	case "GetByUintptr":
+		return func(m I.Map, idx uint64, value interface{}) { m.GetByUintptr(keys.uintptrs[idx]) }
+	case "SwapByUintptr":
+		return func(m I.Map, idx uint64, value interface{}) { m.SwapByUintptr(keys.uintptrs[idx], value) }
+	case "UnsetByUintptr":
+		return func(m I.Map, idx uint64, value interface{}) { m.UnsetByUintptr(keys.uintptrs[idx]) }
+	}
+	panic("Unknown method: " + methodName)
+}
+
+// DoBenchmarkOfTypedKeyMethod benchmarks a method with the key of a known
+// type: action is "Set", "Get", "Swap" or "Unset" and keyType is "String",
+// "Bytes", "Uint64" or "Uintptr" (so the method is action+"By"+keyType).
+// It's the same as DoBenchmarkOfSet, DoBenchmarkOfGet and so on.
+func DoBenchmarkOfTypedKeyMethod(b *testing.B, factoryFunc mapFactoryFunc, blockSize uint64, keyAmount uint64, action string, keyType string) {
+	b.StopTimer()
+	b.ResetTimer()
+
+	keys := generateTypedKeys(keyAmount)
+	call := keys.method(action + "By" + keyType)
+	set := keys.method("SetBy" + keyType)
+	fill := func(m I.Map) {
+		for i := uint64(0); i < keyAmount; i++ {
+			set(m, i, int(i))
+		}
+	}
+
+	m := factoryFunc(blockSize)
+	if action == "Get" || action == "Swap" {
+		fill(m)
+	}
+
+	currentIdx := uint64(0)
+	b.ReportAllocs()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		if currentIdx == 0 && action == "Unset" {
+			b.StopTimer()
+			fill(m)
+			b.StartTimer()
+		}
+
+		if action == "Set" || action == "Swap" {
+			call(m, currentIdx, i)
+		} else {
+			call(m, currentIdx, nil)
+		}
+
+		currentIdx++
+		if currentIdx >= keyAmount {
+			currentIdx = 0
+			if action == "Set" {
+				b.StopTimer()
+				m = factoryFunc(blockSize)
+				b.StartTimer()
+			}
+		}
+	}
+	b.StopTimer()
+}
@@ -14,6 +14,22 @@ type keyStruct struct {
 	Key uint32
 }
 
+// copyKey returns a deep copy of a key generated by generateKeys
+func copyKey(key interface{}) interface{} {
+	switch key := key.(type) {
+	case map[uint32]uint32:
+		result := map[uint32]uint32{}
+		for k, v := range key {
+			result[k] = v
+		}
+		return result
+	case []uint32:
+		return append([]uint32{}, key...)
+	default:
+		return key
+	}
+}
+
 func generateKeys(keyAmount uint64, keyType string) []interface{} {
 	resultMap := map[string]bool{}
 	for uint64(len(resultMap)) < keyAmount {
@@ -203,6 +203,117 @@ func DoTestConcurrency(t *testing.T, factoryFunc mapFactoryFunc) {
 	wg.Wait()
 }
 
+// DoTestConcurrencyWithUnset is the same as DoTestConcurrency, but the keys
+// are also removed concurrently (while the map is growing). Every odd key
+// is kept and every even key is removed, so in the end it's checked that
+// the removed keys didn't come back and the kept ones are still in place.
+func DoTestConcurrencyWithUnset(t *testing.T, factoryFunc mapFactoryFunc) {
+	blockSize := uint64(4)
+	m := factoryFunc(blockSize)
+
+	concurrency := 64
+	keysPerRoutine := 4096
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for routineIdx := 0; routineIdx < concurrency; routineIdx++ {
+		go func(routineIdx int) {
+			defer wg.Done()
+			for i := routineIdx * keysPerRoutine; i < (routineIdx+1)*keysPerRoutine; i++ {
+				var key I.Key = i
+				if routineIdx%2 == 0 {
+					key = fmt.Sprint(i)
+				}
+				err := m.Set(key, i)
+				if err != nil {
+					t.Errorf("Cannot m.Set(%v, %v): %v", key, i, err)
+				}
+				r, err := m.Get(key)
+				if err != nil {
+					t.Errorf("Cannot m.Get(%v): %v", key, err)
+				} else if r != i {
+					t.Errorf("%v != %v", i, r)
+				}
+				if i%2 != 0 {
+					continue
+				}
+				err = m.Unset(key)
+				if err != nil {
+					t.Errorf("Cannot m.Unset(%v): %v", key, err)
+				}
+				_, err = m.Get(key)
+				if err != errors.NotFound {
+					t.Errorf(`An expected "NotFound" error for %v, but got: %v`, key, err)
+				}
+			}
+		}(routineIdx)
+	}
+
+	wg.Wait()
+
+	if m.Len() != concurrency*keysPerRoutine/2 && m.Len() != -1 { // "-1" means "unsupported"
+		t.Errorf("m.Len() is not %v: %v", concurrency*keysPerRoutine/2, m.Len())
+	}
+	for i := 0; i < concurrency*keysPerRoutine; i++ {
+		var key I.Key = i
+		if (i/keysPerRoutine)%2 == 0 {
+			key = fmt.Sprint(i)
+		}
+		r, err := m.Get(key)
+		if i%2 == 0 {
+			if err != errors.NotFound {
+				t.Errorf("The removed key %v came back: %v %v", key, r, err)
+			}
+			continue
+		}
+		if err != nil || r != i {
+			t.Errorf("Cannot m.Get(%v): %v %v", key, r, err)
+		}
+	}
+	if m, ok := m.(checkConsistencier); ok {
+		err := m.CheckConsistency()
+		if err != nil {
+			t.Errorf("Got an unexpected error: %v", err)
+		}
+	}
+}
+
+// DoTestKeyTypes checks the keys of all the types generated by generateKeys.
+// The keys are looked up by their copies, so the keys of reference types
+// (maps, slices) should be compared by their content.
+func DoTestKeyTypes(t *testing.T, factoryFunc mapFactoryFunc) {
+	keyAmount := 4096
+	for _, keyType := range []string{"int", "string", "struct", "slice", "map"} {
+		m := factoryFunc(1024)
+		keys := generateKeys(uint64(keyAmount), keyType)
+		for i, key := range keys {
+			if err := m.Set(key, i); err != nil {
+				t.Fatalf("%v: m.Set(%v): %v", keyType, key, err)
+			}
+		}
+		for i := 0; i < keyAmount; i += 2 {
+			if err := m.Unset(copyKey(keys[i])); err != nil {
+				t.Errorf("%v: m.Unset(%v): %v", keyType, keys[i], err)
+			}
+		}
+		for i, key := range keys {
+			_, err := m.Get(copyKey(key))
+			if i%2 == 0 {
+				if err != errors.NotFound {
+					t.Errorf(`%v: An expected "NotFound" error, but got: %v`, keyType, err)
+				}
+				continue
+			}
+			expect(t, m, copyKey(key), i)
+		}
+		if m.Len() != keyAmount/2 {
+			t.Errorf("%v: m.Len() != %v: %v", keyType, keyAmount/2, m.Len())
+		}
+		if err := m.(checkConsistencier).CheckConsistency(); err != nil {
+			t.Errorf("%v: %v", keyType, err)
+		}
+	}
+}
+
 func tryHashCollisions(customHasher I.Hasher, blockSize uint64, keys []interface{}) int {
 	alreadyIsSet := map[uint64]bool{}
 
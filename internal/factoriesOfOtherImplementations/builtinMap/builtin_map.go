@@ -77,6 +77,48 @@ func (m *builtinMap) GetByBytes(key []byte) (value interface{}, err error) {
 func (m *builtinMap) GetByUint64(key uint64) (value interface{}, err error) {
 	return nil, ErrNotImplemented
 }
+func (m *builtinMap) SetByString(key string, value interface{}) error {
+	return ErrNotImplemented
+}
+func (m *builtinMap) GetByString(key string) (value interface{}, err error) {
+	return nil, ErrNotImplemented
+}
+func (m *builtinMap) SwapByString(key string, value interface{}) (interface{}, error) {
+	return nil, ErrNotImplemented
+}
+func (m *builtinMap) UnsetByString(key string) error {
+	return ErrNotImplemented
+}
+func (m *builtinMap) SetByBytes(key []byte, value interface{}) error {
+	return ErrNotImplemented
+}
+func (m *builtinMap) SwapByBytes(key []byte, value interface{}) (interface{}, error) {
+	return nil, ErrNotImplemented
+}
+func (m *builtinMap) UnsetByBytes(key []byte) error {
+	return ErrNotImplemented
+}
+func (m *builtinMap) SetByUint64(key uint64, value interface{}) error {
+	return ErrNotImplemented
+}
+func (m *builtinMap) SwapByUint64(key uint64, value interface{}) (interface{}, error) {
+	return nil, ErrNotImplemented
+}
+func (m *builtinMap) UnsetByUint64(key uint64) error {
+	return ErrNotImplemented
+}
+func (m *builtinMap) SetByUintptr(key uintptr, value interface{}) error {
+	return ErrNotImplemented
+}
+func (m *builtinMap) GetByUintptr(key uintptr) (value interface{}, err error) {
+	return nil, ErrNotImplemented
+}
+func (m *builtinMap) SwapByUintptr(key uintptr, value interface{}) (interface{}, error) {
+	return nil, ErrNotImplemented
+}
+func (m *builtinMap) UnsetByUintptr(key uintptr) error {
+	return ErrNotImplemented
+}
 func (m *builtinMap) Keys() []interface{} {
 	return nil
 }
@@ -68,6 +68,48 @@ func (m *builtinSyncMap) GetByBytes(key []byte) (value interface{}, err error) {
 func (m *builtinSyncMap) GetByUint64(key uint64) (value interface{}, err error) {
 	return nil, ErrNotImplemented
 }
+func (m *builtinSyncMap) SetByString(key string, value interface{}) error {
+	return ErrNotImplemented
+}
+func (m *builtinSyncMap) GetByString(key string) (value interface{}, err error) {
+	return nil, ErrNotImplemented
+}
+func (m *builtinSyncMap) SwapByString(key string, value interface{}) (interface{}, error) {
+	return nil, ErrNotImplemented
+}
+func (m *builtinSyncMap) UnsetByString(key string) error {
+	return ErrNotImplemented
+}
+func (m *builtinSyncMap) SetByBytes(key []byte, value interface{}) error {
+	return ErrNotImplemented
+}
+func (m *builtinSyncMap) SwapByBytes(key []byte, value interface{}) (interface{}, error) {
+	return nil, ErrNotImplemented
+}
+func (m *builtinSyncMap) UnsetByBytes(key []byte) error {
+	return ErrNotImplemented
+}
+func (m *builtinSyncMap) SetByUint64(key uint64, value interface{}) error {
+	return ErrNotImplemented
+}
+func (m *builtinSyncMap) SwapByUint64(key uint64, value interface{}) (interface{}, error) {
+	return nil, ErrNotImplemented
+}
+func (m *builtinSyncMap) UnsetByUint64(key uint64) error {
+	return ErrNotImplemented
+}
+func (m *builtinSyncMap) SetByUintptr(key uintptr, value interface{}) error {
+	return ErrNotImplemented
+}
+func (m *builtinSyncMap) GetByUintptr(key uintptr) (value interface{}, err error) {
+	return nil, ErrNotImplemented
+}
+func (m *builtinSyncMap) SwapByUintptr(key uintptr, value interface{}) (interface{}, error) {
+	return nil, ErrNotImplemented
+}
+func (m *builtinSyncMap) UnsetByUintptr(key uintptr) error {
+	return ErrNotImplemented
+}
 func (m *builtinSyncMap) Keys() []interface{} {
 	return nil
 }
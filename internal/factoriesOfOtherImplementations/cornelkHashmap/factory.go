@@ -56,6 +56,48 @@ func (m *hashmapWrapper) GetByBytes(key []byte) (value interface{}, err error) {
 func (m *hashmapWrapper) GetByUint64(key uint64) (value interface{}, err error) {
 	return nil, ErrNotImplemented
 }
+func (m *hashmapWrapper) SetByString(key string, value interface{}) error {
+	return ErrNotImplemented
+}
+func (m *hashmapWrapper) GetByString(key string) (value interface{}, err error) {
+	return nil, ErrNotImplemented
+}
+func (m *hashmapWrapper) SwapByString(key string, value interface{}) (interface{}, error) {
+	return nil, ErrNotImplemented
+}
+func (m *hashmapWrapper) UnsetByString(key string) error {
+	return ErrNotImplemented
+}
+func (m *hashmapWrapper) SetByBytes(key []byte, value interface{}) error {
+	return ErrNotImplemented
+}
+func (m *hashmapWrapper) SwapByBytes(key []byte, value interface{}) (interface{}, error) {
+	return nil, ErrNotImplemented
+}
+func (m *hashmapWrapper) UnsetByBytes(key []byte) error {
+	return ErrNotImplemented
+}
+func (m *hashmapWrapper) SetByUint64(key uint64, value interface{}) error {
+	return ErrNotImplemented
+}
+func (m *hashmapWrapper) SwapByUint64(key uint64, value interface{}) (interface{}, error) {
+	return nil, ErrNotImplemented
+}
+func (m *hashmapWrapper) UnsetByUint64(key uint64) error {
+	return ErrNotImplemented
+}
+func (m *hashmapWrapper) SetByUintptr(key uintptr, value interface{}) error {
+	return ErrNotImplemented
+}
+func (m *hashmapWrapper) GetByUintptr(key uintptr) (value interface{}, err error) {
+	return nil, ErrNotImplemented
+}
+func (m *hashmapWrapper) SwapByUintptr(key uintptr, value interface{}) (interface{}, error) {
+	return nil, ErrNotImplemented
+}
+func (m *hashmapWrapper) UnsetByUintptr(key uintptr) error {
+	return ErrNotImplemented
+}
 func (m *hashmapWrapper) Unset(key I.Key) error {
 	m.HashMap.Del(key)
 	return nil
@@ -0,0 +1,35 @@
+package atomicmap
+
+import (
+	"iter"
+)
+
+// All returns an iterator over the keys and values of the map.
+//
+// The entries are taken lazily from the storage (see Range for the
+// consistency notes). A slot is released before the entry is yielded,
+// so it's safe to stop the iteration with "break" at any moment.
+func (m *openAddressGrowingMap) All() iter.Seq2[Key, interface{}] {
+	return func(yield func(Key, interface{}) bool) {
+		m.Range(yield)
+	}
+}
+
+// KeysSeq is a lazy variant of Keys: it returns an iterator over the keys
+// of the map. See All.
+func (m *openAddressGrowingMap) KeysSeq() iter.Seq[Key] {
+	return func(yield func(Key) bool) {
+		m.Range(func(key Key, _ interface{}) bool {
+			return yield(key)
+		})
+	}
+}
+
+// Values returns an iterator over the values of the map. See All.
+func (m *openAddressGrowingMap) Values() iter.Seq[interface{}] {
+	return func(yield func(interface{}) bool) {
+		m.Range(func(_ Key, value interface{}) bool {
+			return yield(value)
+		})
+	}
+}
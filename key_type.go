@@ -0,0 +1,60 @@
+package atomicmap
+
+import (
+	"reflect"
+)
+
+var (
+	stringKeyType  = reflect.TypeOf("")
+	bytesKeyType   = reflect.TypeOf([]byte(nil))
+	uint64KeyType  = reflect.TypeOf(uint64(0))
+	uintptrKeyType = reflect.TypeOf(uintptr(0))
+)
+
+// preHashKey is preHash for the methods which reject the keys of a wrong
+// type: if the key type is pinned (see WithKeyType), the key is checked and
+// pre-hashed by one call of the strict hasher, and a *WrongKeyTypeError is
+// returned for a key of another type.
+func (m *openAddressGrowingMap) preHashKey(key Key) (uint64, uint8, bool, error) {
+	if m.strictHasher == nil {
+		preHashValue, typeID, preHashValueIsFull := m.preHash(key)
+		return preHashValue, typeID, preHashValueIsFull, nil
+	}
+	preHashValue, typeID, preHashValueIsFull, ok := m.strictHasher.PreHashKey(key)
+	if !ok {
+		return 0, 0, false, m.wrongKeyType(reflect.TypeOf(key))
+	}
+	return preHashValue, typeID, preHashValueIsFull, nil
+}
+
+// checkKey returns a *WrongKeyTypeError if the key type is pinned (see
+// WithKeyType) and the key is of another type. Use preHashKey if the key
+// is hashed anyway.
+func (m *openAddressGrowingMap) checkKey(key Key) error {
+	if m.strictHasher == nil || m.strictHasher.IsKeyOfType(key) {
+		return nil
+	}
+	return m.wrongKeyType(reflect.TypeOf(key))
+}
+
+// checkKeyType is checkKey for the methods with the keys of a fixed type
+// (like GetByUint64).
+func (m *openAddressGrowingMap) checkKeyType(keyType reflect.Type) error {
+	if m.strictHasher == nil || m.strictHasher.KeyType() == keyType {
+		return nil
+	}
+	return m.wrongKeyType(keyType)
+}
+
+func (m *openAddressGrowingMap) wrongKeyType(keyType reflect.Type) error {
+	return &WrongKeyTypeError{Expected: m.strictHasher.KeyType(), Actual: keyType}
+}
+
+// KeyType returns the pinned type of the keys (see WithKeyType) or nil if
+// the keys of any types are allowed.
+func (m *openAddressGrowingMap) KeyType() reflect.Type {
+	if m.strictHasher == nil {
+		return nil
+	}
+	return m.strictHasher.KeyType()
+}
@@ -0,0 +1,204 @@
+package atomicmap
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/xaionaro-go/spinlock"
+)
+
+// mapControl is the map-level synchronization state: the counters and the
+// flags used to coordinate writers with the growing procedure. It's shared
+// by all the map implementations in this package.
+type mapControl struct {
+	busySlots    int64
+	removedSlots int64 // the amount of tombstones (slots in state "removed")
+
+	writeConcurrency int32
+	threadSafety     bool
+	forbidGrowing    int32
+	resizeDeferred   int32 // a resize was refused because growing was forbidden
+	isGrowing        int32
+	shrinkAtFullness float64
+	growAtFullness   float64 // the load factor, see WithLoadFactor
+	growthFactor     uint64
+	maxSize          uint64
+	locker           spinlock.Locker
+}
+
+func (m *mapControl) SetThreadSafety(threadSafety bool) {
+	m.threadSafety = threadSafety
+}
+
+func (m *mapControl) IsForbiddenToGrow() bool {
+	return atomic.LoadInt32(&m.forbidGrowing) != 0
+}
+
+// setForbidGrowing switches the flag "forbidGrowing". After forbidding it
+// waits until the current resize (if any) is finished, so no resize happens
+// after it returns.
+func (m *mapControl) setForbidGrowing(forbidGrowing bool) {
+	if !forbidGrowing {
+		atomic.StoreInt32(&m.forbidGrowing, 0)
+		return
+	}
+	atomic.StoreInt32(&m.forbidGrowing, 1)
+	m.concedeToGrowing()
+}
+
+// checkResizeAllowed returns ForbiddenToGrow if growing is forbidden. In this
+// case the resize is remembered as deferred (see takeDeferredResize).
+//
+// It should be checked again after the map is taken exclusively, otherwise
+// a resize may start after setForbidGrowing(true) is returned.
+func (m *mapControl) checkResizeAllowed() error {
+	if !m.IsForbiddenToGrow() {
+		return nil
+	}
+	atomic.StoreInt32(&m.resizeDeferred, 1)
+	return ForbiddenToGrow
+}
+
+// takeDeferredResize reports (and resets) if a resize was refused while
+// growing was forbidden.
+func (m *mapControl) takeDeferredResize() bool {
+	return atomic.CompareAndSwapInt32(&m.resizeDeferred, 1, 0)
+}
+
+// SetShrinkAtFullness enables automatic shrinking of the storage: if after
+// removing a key the fullness (the amount of keys divided by the size of the
+// storage) is lower than "fullness" then the storage is shrunk (but not
+// below the initial size). The storage is shrunk to be approximately half
+// of the load factor full (see WithLoadFactor), so "fullness" should be
+// noticeably lower than the load factor to avoid resizing back and forth.
+//
+// 0 disables automatic shrinking (the default). It returns an error
+// (wrapping InvalidArgument) if "fullness" is not in range [0, load factor).
+// It should be called before the map is used concurrently (or use
+// WithShrinkAtFullness).
+func (m *mapControl) SetShrinkAtFullness(fullness float64) error {
+	if math.IsNaN(fullness) || fullness < 0 || fullness >= m.growAtFullness {
+		return fmt.Errorf("%w: the shrink fullness should be in range [0, %v), got %v", InvalidArgument, m.growAtFullness, fullness)
+	}
+	m.shrinkAtFullness = fullness
+	return nil
+}
+
+// autoShrinkSize returns the size the storage should be automatically shrunk
+// to, or 0 if it shouldn't be shrunk.
+func (m *mapControl) autoShrinkSize(currentSize, minSize uint64) uint64 {
+	if m.shrinkAtFullness <= 0 || currentSize <= minSize {
+		return 0
+	}
+	busySlots := uint64(atomic.LoadInt64(&m.busySlots))
+	if float64(busySlots)/float64(currentSize) >= m.shrinkAtFullness {
+		return 0
+	}
+	newSize := powerOfTwoGE(uint64(float64(busySlots)/m.growAtFullness*2) + 1)
+	if newSize < minSize {
+		newSize = minSize
+	}
+	if newSize >= currentSize {
+		return 0
+	}
+	return newSize
+}
+
+func (m *mapControl) lock() {
+	if !m.threadSafety {
+		return
+	}
+	m.locker.Lock()
+}
+func (m *mapControl) unlock() {
+	if !m.threadSafety {
+		return
+	}
+	m.locker.Unlock()
+}
+
+func (m *mapControl) waitUntilNoWrite() {
+	for atomic.LoadInt32(&m.writeConcurrency) != 0 {
+		time.Sleep(lockSleepInterval)
+	}
+}
+
+// occupiedSlots returns the amount of slots which are not free: the slots
+// with keys, the tombstones and the slots which may be being taken right
+// now by the writers.
+func (m *mapControl) occupiedSlots() uint64 {
+	return uint64(atomic.LoadInt64(&m.busySlots) + atomic.LoadInt64(&m.removedSlots) + int64(atomic.LoadInt32(&m.writeConcurrency)))
+}
+
+// grownSize returns the size the storage of size "size" should be grown to
+// (see WithGrowthFactor and WithMaxSize). It returns NoSpaceLeft if the
+// storage is already of the maximal size.
+func (m *mapControl) grownSize(size uint64) (uint64, error) {
+	if size >= m.maxSize {
+		return 0, NoSpaceLeft
+	}
+	if size > m.maxSize/m.growthFactor {
+		return m.maxSize, nil
+	}
+	return size * m.growthFactor, nil
+}
+
+// shouldDropTombstones reports whether the lack of free space should be
+// solved by rehashing the map into a storage of the same size (to get rid
+// of the tombstones) instead of growing it. That's the case if the most of
+// the occupied slots are tombstones (or if growing is forbidden).
+func (m *mapControl) shouldDropTombstones(size uint64) bool {
+	if atomic.LoadInt64(&m.removedSlots) == 0 {
+		return false
+	}
+	return m.IsForbiddenToGrow() || float64(atomic.LoadInt64(&m.busySlots))/float64(size) < m.growAtFullness/2
+}
+
+// enterWriteSection registers the caller as a writer which may take up to
+// "amount" slots, so a structural change (see beginExclusive) will wait for
+// it. If a structural change is already in progress then it waits until
+// the change is finished.
+//
+// writeConcurrency is increased before checking isGrowing, while
+// beginExclusive sets isGrowing before checking writeConcurrency. So either
+// the writer sees the structural change or the change sees the writer.
+func (m *mapControl) enterWriteSection(amount int32) {
+	for {
+		atomic.AddInt32(&m.writeConcurrency, amount)
+		if atomic.LoadInt32(&m.isGrowing) == 0 {
+			return
+		}
+		atomic.AddInt32(&m.writeConcurrency, -amount)
+		m.concedeToGrowing()
+	}
+}
+
+func (m *mapControl) leaveWriteSection(amount int32) {
+	atomic.AddInt32(&m.writeConcurrency, -amount)
+}
+
+// beginExclusive takes the map for a structural change (growing, clearing):
+// it raises the "isGrowing" flag (so new writers will wait) and waits until
+// the current writers are gone. It returns false if somebody else is already
+// doing a structural change.
+func (m *mapControl) beginExclusive() bool {
+	if !atomic.CompareAndSwapInt32(&m.isGrowing, 0, 1) {
+		return false
+	}
+	m.lock()
+	m.waitUntilNoWrite()
+	return true
+}
+
+func (m *mapControl) endExclusive() {
+	m.unlock()
+	atomic.StoreInt32(&m.isGrowing, 0)
+}
+
+func (m *mapControl) concedeToGrowing() {
+	for atomic.LoadInt32(&m.isGrowing) != 0 {
+		time.Sleep(lockSleepInterval)
+	}
+}
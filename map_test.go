@@ -0,0 +1,2293 @@
+package atomicmap
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/xaionaro-go/atomicmap/hasher"
+)
+
+func TestRange(t *testing.T) {
+	m := NewWithArgs(16)
+	m.Range(func(key Key, value interface{}) bool {
+		t.Errorf("An empty map, but got: %v: %v", key, value)
+		return true
+	})
+
+	for i := 0; i < 1024; i++ {
+		m.Set(i, i*2)
+	}
+	m.SetBytesByBytes([]byte("key"), []byte("value"))
+	m.Unset(5)
+
+	visited := map[Key]int{}
+	m.Range(func(key Key, value interface{}) bool {
+		if keyBytes, ok := key.([]byte); ok {
+			if string(value.([]byte)) != "value" {
+				t.Errorf("A wrong value for key %q: %v", keyBytes, value)
+			}
+			visited[string(keyBytes)]++
+			return true
+		}
+		if value != key.(int)*2 {
+			t.Errorf("A wrong value for key %v: %v", key, value)
+		}
+		visited[key]++
+		return true
+	})
+	if len(visited) != m.Len() {
+		t.Errorf("len(visited) != m.Len(): %d != %d", len(visited), m.Len())
+	}
+	for key, count := range visited {
+		if count != 1 {
+			t.Errorf("Key %v was visited %d times", key, count)
+		}
+	}
+	if _, ok := visited[5]; ok {
+		t.Errorf("A removed key was visited")
+	}
+
+	count := 0
+	m.Range(func(key Key, value interface{}) bool {
+		count++
+		return count < 10
+	})
+	if count != 10 {
+		t.Errorf("Range() didn't stop: %v", count)
+	}
+
+	// The callback is allowed to modify the map
+	m.Range(func(key Key, value interface{}) bool {
+		if _, ok := key.(int); ok {
+			m.Set(key, -1)
+		}
+		return true
+	})
+	for i := 0; i < 1024; i++ {
+		if i == 5 {
+			continue
+		}
+		if v, err := m.Get(i); v != -1 || err != nil {
+			t.Errorf("m.Get(%v): %v %v", i, v, err)
+		}
+	}
+}
+
+func TestIterators(t *testing.T) {
+	m := NewWithArgs(16)
+	for i := 0; i < 100; i++ {
+		m.Set(i, i)
+	}
+
+	visited := map[Key]interface{}{}
+	for key, value := range m.All() {
+		visited[key] = value
+	}
+	if len(visited) != 100 {
+		t.Errorf("len(visited) != 100: %v", len(visited))
+	}
+
+	keySum, valueSum := 0, 0
+	for key := range m.KeysSeq() {
+		keySum += key.(int)
+	}
+	for value := range m.Values() {
+		valueSum += value.(int)
+	}
+	if keySum != 99*100/2 || valueSum != 99*100/2 {
+		t.Errorf("Wrong sums: %v %v", keySum, valueSum)
+	}
+
+	// An abandoned iteration should not leave the slot with a reader, otherwise
+	// the next Set() of the key will wait for the reader forever.
+	var lastKey Key
+	for key := range m.All() {
+		lastKey = key
+		break
+	}
+	if err := m.Set(lastKey, -1); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+	if v, err := m.Get(lastKey); v != -1 || err != nil {
+		t.Errorf("m.Get(%v): %v %v", lastKey, v, err)
+	}
+}
+
+// collidingIntKeys returns two int keys which are placed to the same
+// slot index of the map.
+func collidingIntKeys(m Map) (int, int) {
+	keyByIdx := map[uint64]int{}
+	for key := 0; ; key++ {
+		idxValue := m.lastStorage().getIdx(hasher.Hash(key))
+		if otherKey, ok := keyByIdx[idxValue]; ok {
+			return otherKey, key
+		}
+		keyByIdx[idxValue] = key
+	}
+}
+
+func TestGetOrSet(t *testing.T) {
+	m := NewWithArgs(16)
+
+	actual, loaded, err := m.GetOrSet("key", 1)
+	if actual != 1 || loaded || err != nil {
+		t.Errorf("m.GetOrSet(): %v %v %v", actual, loaded, err)
+	}
+	actual, loaded, err = m.GetOrSet("key", 2)
+	if actual != 1 || !loaded || err != nil {
+		t.Errorf("m.GetOrSet(): %v %v %v", actual, loaded, err)
+	}
+
+	// The key is behind a removed slot in the chain: it should be found
+	// instead of reusing the removed slot.
+	keyA, keyB := collidingIntKeys(m)
+	m.Set(keyA, "a")
+	m.Set(keyB, "b")
+	m.Unset(keyA)
+	actual, loaded, err = m.GetOrSet(keyB, "c")
+	if actual != "b" || !loaded || err != nil {
+		t.Errorf("m.GetOrSet(): %v %v %v", actual, loaded, err)
+	}
+	if m.Len() != 2 {
+		t.Errorf("m.Len() is not 2: %v", m.Len())
+	}
+	m.Set(keyB, "d")
+	m.Unset(keyB)
+	if _, err := m.Get(keyB); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+}
+
+func TestGetOrSetConcurrency(t *testing.T) {
+	m := NewWithArgs(4)
+
+	keyAmount := 1024
+	concurrency := 16
+	results := make([][]interface{}, concurrency)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for c := 0; c < concurrency; c++ {
+		results[c] = make([]interface{}, keyAmount)
+		go func(c int) {
+			defer wg.Done()
+			for i := 0; i < keyAmount; i++ {
+				actual, _, err := m.GetOrSet(i, c)
+				if err != nil {
+					t.Errorf("Cannot m.GetOrSet(%v, %v): %v", i, c, err)
+				}
+				results[c][i] = actual
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if m.Len() != keyAmount {
+		t.Errorf("m.Len() != %v: %v", keyAmount, m.Len())
+	}
+	for i := 0; i < keyAmount; i++ {
+		value, _ := m.Get(i)
+		for c := 0; c < concurrency; c++ {
+			if results[c][i] != value {
+				t.Errorf("Different values for key %v: %v != %v", i, results[c][i], value)
+			}
+		}
+	}
+}
+
+func TestUnsetNotFound(t *testing.T) {
+	m := NewWithArgs(16)
+	m.Set(1, 1)
+	if err := m.Unset(2); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	m := NewWithArgs(16)
+
+	if _, err := m.CompareAndSwap("key", 1, 2); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+
+	m.Set("key", 1)
+	swapped, err := m.CompareAndSwap("key", 2, 3)
+	if swapped || err != nil {
+		t.Errorf("m.CompareAndSwap(): %v %v", swapped, err)
+	}
+	swapped, err = m.CompareAndSwap("key", 1, 3)
+	if !swapped || err != nil {
+		t.Errorf("m.CompareAndSwap(): %v %v", swapped, err)
+	}
+	if v, _ := m.Get("key"); v != 3 {
+		t.Errorf("A wrong value: %v", v)
+	}
+
+	m.SetBytesByBytes([]byte("bytes"), []byte("a"))
+	swapped, err = m.CompareAndSwap([]byte("bytes"), []byte("a"), []byte("b"))
+	if !swapped || err != nil {
+		t.Errorf("m.CompareAndSwap(): %v %v", swapped, err)
+	}
+
+	isEqualSlice := func(a, b interface{}) bool {
+		return a.([]int)[0] == b.([]int)[0]
+	}
+	m.Set("slice", []int{1})
+	swapped, err = m.CompareAndSwapFunc("slice", []int{1}, []int{2}, isEqualSlice)
+	if !swapped || err != nil {
+		t.Errorf("m.CompareAndSwapFunc(): %v %v", swapped, err)
+	}
+	deleted, err := m.CompareAndDeleteFunc("slice", []int{1}, isEqualSlice)
+	if deleted || err != nil {
+		t.Errorf("m.CompareAndDeleteFunc(): %v %v", deleted, err)
+	}
+	deleted, err = m.CompareAndDeleteFunc("slice", []int{2}, isEqualSlice)
+	if !deleted || err != nil {
+		t.Errorf("m.CompareAndDeleteFunc(): %v %v", deleted, err)
+	}
+
+	deleted, err = m.CompareAndDelete("key", 1)
+	if deleted || err != nil {
+		t.Errorf("m.CompareAndDelete(): %v %v", deleted, err)
+	}
+	deleted, err = m.CompareAndDelete("key", 3)
+	if !deleted || err != nil {
+		t.Errorf("m.CompareAndDelete(): %v %v", deleted, err)
+	}
+	if _, err := m.CompareAndDelete("key", 3); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+}
+
+func TestCompareAndSwapConcurrency(t *testing.T) {
+	m := NewWithArgs(16)
+	m.Set("counter", 0)
+
+	concurrency := 16
+	increments := 1000
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for c := 0; c < concurrency; c++ {
+		go func() {
+			defer wg.Done()
+			for i := 0; i < increments; {
+				v, _ := m.Get("counter")
+				swapped, err := m.CompareAndSwap("counter", v, v.(int)+1)
+				if err != nil {
+					t.Errorf("Got an unexpected error: %v", err)
+					return
+				}
+				if swapped {
+					i++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v, _ := m.Get("counter"); v != concurrency*increments {
+		t.Errorf("A wrong value: %v", v)
+	}
+}
+
+func TestCompareAndSwapPanic(t *testing.T) {
+	m := NewWithArgs(16)
+	m.Set(1, []int{1})
+	m.Set(2, 2)
+
+	isPanicked := func(f func()) (result bool) {
+		defer func() {
+			result = recover() != nil
+		}()
+		f()
+		return
+	}
+
+	// []int values are not comparable with "==", and a panic should not
+	// leave the slot of the key locked.
+	if !isPanicked(func() { m.CompareAndSwap(1, []int{1}, []int{2}) }) {
+		t.Errorf("CompareAndSwap of []int values should panic")
+	}
+	if !isPanicked(func() {
+		m.UnsetIf(2, func(value interface{}) bool { panic("test") })
+	}) {
+		t.Errorf("UnsetIf should pass the panic of conditionFunc")
+	}
+	if !isPanicked(func() {
+		m.CompareAndDeleteFunc(2, 2, func(currentValue, expectedValue interface{}) bool { panic("test") })
+	}) {
+		t.Errorf("CompareAndDeleteFunc should pass the panic of isEqual")
+	}
+
+	if err := m.Set(1, 5); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+	if v, err := m.Get(1); v != 5 || err != nil {
+		t.Errorf("m.Get(1): %v %v", v, err)
+	}
+	if deleted, err := m.CompareAndDelete(2, 2); !deleted || err != nil {
+		t.Errorf("m.CompareAndDelete(2): %v %v", deleted, err)
+	}
+	if m.Len() != 1 {
+		t.Errorf("m.Len() is not 1: %v", m.Len())
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	m := NewWithArgs(16)
+
+	increment := func(oldValue interface{}, exists bool) (interface{}, bool) {
+		if !exists {
+			return 1, true
+		}
+		return oldValue.(int) + 1, true
+	}
+	m.Update("counter", increment)
+	m.Update("counter", increment)
+	if v, err := m.Get("counter"); v != 2 || err != nil {
+		t.Errorf("m.Get(): %v %v", v, err)
+	}
+
+	// A new key is not added if fn doesn't want to keep it
+	err := m.Update("absent", func(oldValue interface{}, exists bool) (interface{}, bool) {
+		if exists {
+			t.Errorf("The key should not exist")
+		}
+		return nil, false
+	})
+	if err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+	if _, err := m.Get("absent"); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+	if m.Len() != 1 {
+		t.Errorf("m.Len() is not 1: %v", m.Len())
+	}
+
+	// An existing key is removed if fn doesn't want to keep it
+	m.Update("counter", func(oldValue interface{}, exists bool) (interface{}, bool) {
+		return nil, oldValue.(int) != 2
+	})
+	if _, err := m.Get("counter"); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+	if m.Len() != 0 {
+		t.Errorf("m.Len() is not 0: %v", m.Len())
+	}
+}
+
+func TestUpdateTombstonesReuse(t *testing.T) {
+	m := NewWithArgs(64)
+
+	// Leaving tombstones by cancelled adds and by removals of int keys
+	for i := 0; i < 40; i++ {
+		m.Update(i, func(oldValue interface{}, exists bool) (interface{}, bool) {
+			return nil, false
+		})
+		m.Set(i+40, i)
+		m.Update(i+40, func(oldValue interface{}, exists bool) (interface{}, bool) {
+			return nil, false
+		})
+	}
+
+	// Long strings are not full keys, so they must not inherit the
+	// pre-hashes of the keys previously stored in the reused slots.
+	for i := 0; i < 40; i++ {
+		m.Set(strings.Repeat("x", 16)+strconv.Itoa(i), i)
+	}
+	for i := 0; i < 40; i++ {
+		key := strings.Repeat("x", 16) + strconv.Itoa(i)
+		if v, err := m.Get(key); err != nil || v != i {
+			t.Errorf("m.Get(%v): %v %v", key, v, err)
+		}
+	}
+	if m.Len() != 40 {
+		t.Errorf("m.Len() is not 40: %v", m.Len())
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+}
+
+func TestUpdateConcurrency(t *testing.T) {
+	m := NewWithArgs(4)
+
+	keyAmount := 64
+	concurrency := 16
+	increments := 100
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for c := 0; c < concurrency; c++ {
+		go func(c int) {
+			defer wg.Done()
+			for i := 0; i < increments; i++ {
+				for key := 0; key < keyAmount; key++ {
+					err := m.Update(key, func(oldValue interface{}, exists bool) (interface{}, bool) {
+						if !exists {
+							return []int{c}, true
+						}
+						return append(oldValue.([]int), c), true
+					})
+					if err != nil {
+						t.Errorf("Got an unexpected error: %v", err)
+					}
+				}
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	for key := 0; key < keyAmount; key++ {
+		v, err := m.Get(key)
+		if err != nil {
+			t.Errorf("m.Get(%v): %v", key, err)
+			continue
+		}
+		if len(v.([]int)) != concurrency*increments {
+			t.Errorf("A wrong length for key %v: %v", key, len(v.([]int)))
+		}
+	}
+}
+
+func TestUpdatePanic(t *testing.T) {
+	m := NewWithArgs(16)
+	m.Set(1, 1)
+
+	isPanicked := func(f func()) (result bool) {
+		defer func() {
+			result = recover() != nil
+		}()
+		f()
+		return
+	}
+
+	// A panic of fn should neither leave the slot of the key locked nor
+	// leave the writer registered (the map could not grow then).
+	for _, key := range []int{1, 2} {
+		if !isPanicked(func() {
+			m.Update(key, func(oldValue interface{}, exists bool) (interface{}, bool) { panic("test") })
+		}) {
+			t.Errorf("Update(%v) should pass the panic of fn", key)
+		}
+	}
+	if v, err := m.Get(1); v != 1 || err != nil {
+		t.Errorf("m.Get(1): %v %v", v, err)
+	}
+	if _, err := m.Get(2); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+
+	for i := 0; i < 1024; i++ {
+		if err := m.Set(i, i); err != nil {
+			t.Fatalf("Cannot m.Set(%v): %v", i, err)
+		}
+	}
+	if m.Len() != 1024 {
+		t.Errorf("m.Len() is not 1024: %v", m.Len())
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+}
+
+func TestCounters(t *testing.T) {
+	m := NewWithArgs(16)
+
+	if v, err := m.AddUint64("u", 5); v != 5 || err != nil {
+		t.Errorf("m.AddUint64(): %v %v", v, err)
+	}
+	if v, err := m.AddUint64("u", 2); v != 7 || err != nil {
+		t.Errorf("m.AddUint64(): %v %v", v, err)
+	}
+	if v, err := m.AddInt64("i", -3); v != -3 || err != nil {
+		t.Errorf("m.AddInt64(): %v %v", v, err)
+	}
+	if v, err := m.Get("u"); v != uint64(7) || err != nil {
+		t.Errorf("m.Get(): %v %v", v, err)
+	}
+	if v, err := m.Get("i"); v != int64(-3) || err != nil {
+		t.Errorf("m.Get(): %v %v", v, err)
+	}
+
+	if _, err := m.AddInt64("u", 1); err != WrongValueType {
+		t.Errorf(`An expected "WrongValueType" error, but got: %v`, err)
+	}
+	m.Set("string", "value")
+	if _, err := m.AddUint64("string", 1); err != WrongValueType {
+		t.Errorf(`An expected "WrongValueType" error, but got: %v`, err)
+	}
+
+	// A value of the same type set by Set() becomes a counter
+	m.Set("u2", uint64(10))
+	if v, err := m.AddUint64("u2", 1); v != 11 || err != nil {
+		t.Errorf("m.AddUint64(): %v %v", v, err)
+	}
+	// ... and a counter becomes a usual value after Set()
+	m.Set("u2", "value")
+	if v, err := m.Get("u2"); v != "value" || err != nil {
+		t.Errorf("m.Get(): %v %v", v, err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		m.AddUint64("u", 1)
+	})
+	if allocs != 0 {
+		t.Errorf("Incrementing an existing counter allocates: %v", allocs)
+	}
+
+	// Counters and []byte values survive growing
+	m.SetBytesByBytes([]byte("bytes"), []byte("value"))
+	for i := 0; i < 1024; i++ {
+		m.Set(i, i)
+	}
+	// 7 + 100 runs + 1 warm-up run of AllocsPerRun
+	if v, err := m.Get("u"); v != uint64(108) || err != nil {
+		t.Errorf("m.Get(): %v %v", v, err)
+	}
+	if v, err := m.GetByBytes([]byte("bytes")); err != nil || string(v.([]byte)) != "value" {
+		t.Errorf("m.GetByBytes(): %v %v", v, err)
+	}
+}
+
+func TestCountersConcurrency(t *testing.T) {
+	m := NewWithArgs(4)
+
+	keyAmount := 256
+	concurrency := 16
+	increments := 100
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for c := 0; c < concurrency; c++ {
+		go func() {
+			defer wg.Done()
+			for i := 0; i < increments; i++ {
+				for key := 0; key < keyAmount; key++ {
+					if _, err := m.AddInt64(key, 1); err != nil {
+						t.Errorf("Got an unexpected error: %v", err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for key := 0; key < keyAmount; key++ {
+		if v, err := m.Get(key); v != int64(concurrency*increments) || err != nil {
+			t.Errorf("m.Get(%v): %v %v", key, v, err)
+		}
+	}
+}
+
+func TestBatch(t *testing.T) {
+	m := NewWithArgs(16)
+
+	keyAmount := 3 * batchChunkSize
+	keys := make([]Key, keyAmount)
+	values := make([]interface{}, keyAmount)
+	for i := range keys {
+		keys[i] = i
+		values[i] = i * 2
+	}
+	if errs := m.SetMany(keys, values); errs != nil {
+		t.Errorf("Got unexpected errors: %v", errs)
+	}
+	if m.Len() != keyAmount {
+		t.Errorf("m.Len() != %v: %v", keyAmount, m.Len())
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+
+	gotValues, errs := m.GetMany(append(keys, "absent"))
+	if len(errs) != keyAmount+1 || errs[keyAmount] != NotFound {
+		t.Errorf("An expected NotFound error for the absent key, but got: %v", errs)
+	}
+	for i := 0; i < keyAmount; i++ {
+		if errs[i] != nil || gotValues[i] != i*2 {
+			t.Errorf("Key %v: %v %v", i, gotValues[i], errs[i])
+		}
+	}
+
+	errs = m.UnsetMany([]Key{1, 2, "absent"})
+	if len(errs) != 3 || errs[0] != nil || errs[1] != nil || errs[2] != NotFound {
+		t.Errorf("m.UnsetMany(): %v", errs)
+	}
+	if m.Len() != keyAmount-2 {
+		t.Errorf("m.Len() != %v: %v", keyAmount-2, m.Len())
+	}
+	if _, err := m.Get(1); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+}
+
+func TestBatchConcurrency(t *testing.T) {
+	m := NewWithArgs(4)
+
+	concurrency := 8
+	keyAmount := 2 * batchChunkSize
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for c := 0; c < concurrency; c++ {
+		go func(c int) {
+			defer wg.Done()
+			keys := make([]Key, keyAmount)
+			values := make([]interface{}, keyAmount)
+			for i := range keys {
+				keys[i] = c*keyAmount + i
+				values[i] = i
+			}
+			if errs := m.SetMany(keys, values); errs != nil {
+				t.Errorf("Got unexpected errors: %v", errs)
+			}
+			for i := 0; i < 100; i++ {
+				m.Set(-c*100-i-1, i)
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if m.Len() != concurrency*(keyAmount+100) {
+		t.Errorf("m.Len() != %v: %v", concurrency*(keyAmount+100), m.Len())
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+}
+
+func TestUnifiedNumericKeys(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithUnifiedNumericKeys()},
+		{WithUnifiedNumericKeys(), WithProcessSeed()},
+	} {
+		m, err := NewWithOptions(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Set(int64(5), "five")
+		m.Set(int64(-1), "minus one")
+		m.Set(uint64(math.MaxUint64), "max")
+		m.Set(5.5, "five and a half")
+		if m.Len() != 4 {
+			t.Errorf("m.Len() != 4: %v", m.Len())
+		}
+
+		for _, key := range []Key{5, int8(5), uint8(5), int16(5), uint16(5), int32(5), uint32(5), uint(5), uint64(5), uintptr(5), float32(5), 5.0} {
+			if value, err := m.Get(key); err != nil || value != "five" {
+				t.Errorf("m.Get(%T(%v)) == %v, %v", key, key, value, err)
+			}
+		}
+		if value, err := m.GetByUint64(5); err != nil || value != "five" {
+			t.Errorf("m.GetByUint64(5) == %v, %v", value, err)
+		}
+		if value, err := m.GetByUintptr(5); err != nil || value != "five" {
+			t.Errorf("m.GetByUintptr(5) == %v, %v", value, err)
+		}
+		for key, expected := range map[Key]interface{}{-1: "minus one", -1.0: "minus one", uint64(math.MaxUint64): "max", float32(5.5): nil, "5": nil} {
+			value, err := m.Get(key)
+			if expected == nil {
+				if err != NotFound {
+					t.Errorf("m.Get(%T(%v)) should return NotFound, got %v, %v", key, key, value, err)
+				}
+				continue
+			}
+			if err != nil || value != expected {
+				t.Errorf("m.Get(%T(%v)) == %v, %v; expected %v", key, key, value, err, expected)
+			}
+		}
+
+		m.Set(uint8(5), "FIVE")
+		if m.Len() != 4 {
+			t.Errorf("m.Len() != 4 after overwriting: %v", m.Len())
+		}
+		if err := m.Unset(5.0); err != nil {
+			t.Errorf("m.Unset(5.0): %v", err)
+		}
+		if _, err := m.Get(int64(5)); err != NotFound {
+			t.Errorf("int64(5) should be unset with 5.0, got %v", err)
+		}
+		if err := m.CheckConsistency(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	m := NewWithArgs(16)
+	m.Set(int64(5), "five")
+	if _, err := m.Get(5); err != NotFound {
+		t.Errorf("the numeric keys should not be unified by default, got %v", err)
+	}
+	if _, err := NewTypedWithOptions[int, int](WithUnifiedNumericKeys()); !errors.Is(err, InvalidOption) {
+		t.Errorf("WithUnifiedNumericKeys should not be supported by Typed, got %v", err)
+	}
+}
+
+func BenchmarkSetMany(b *testing.B) {
+	keyAmount := 1 << 20
+	keys := make([]Key, keyAmount)
+	values := make([]interface{}, keyAmount)
+	for i := range keys {
+		keys[i] = i
+		values[i] = i
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewWithArgs(16).SetMany(keys, values)
+	}
+}
+
+func BenchmarkFromSTDMap(b *testing.B) {
+	keyAmount := 1 << 20
+	stdMap := make(map[Key]interface{}, keyAmount)
+	for i := 0; i < keyAmount; i++ {
+		stdMap[i] = i
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewWithArgs(16).FromSTDMap(stdMap)
+	}
+}
+
+func TestClear(t *testing.T) {
+	m := NewWithArgs(16)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+	for i := 0; i < 100; i++ {
+		m.Unset(i)
+	}
+	size := m.size()
+
+	m.Clear()
+	if m.Len() != 0 {
+		t.Errorf("m.Len() != 0: %v", m.Len())
+	}
+	if m.size() != size {
+		t.Errorf("m.size() != %v: %v", size, m.size())
+	}
+	if _, err := m.Get(500); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+	for idx := range m.loadStorage().items {
+		if m.loadStorage().items[idx].IsSet() != isSet_notSet {
+			t.Errorf("Slot %v is not empty after m.Clear()", idx)
+		}
+	}
+
+	m.Set(1, 2)
+	if v, err := m.Get(1); err != nil || v != 2 {
+		t.Errorf("m.Get(1): %v %v", v, err)
+	}
+
+	m.Reset()
+	if m.Len() != 0 {
+		t.Errorf("m.Len() != 0: %v", m.Len())
+	}
+	if m.size() != 16 {
+		t.Errorf("m.size() != 16: %v", m.size())
+	}
+	m.Set(1, 3)
+	if v, err := m.Get(1); err != nil || v != 3 {
+		t.Errorf("m.Get(1): %v %v", v, err)
+	}
+}
+
+func TestClearConcurrency(t *testing.T) {
+	m := NewWithArgs(16)
+
+	concurrency := 8
+	var wg sync.WaitGroup
+	wg.Add(concurrency + 1)
+	for c := 0; c < concurrency; c++ {
+		go func(c int) {
+			defer wg.Done()
+			for i := 0; i < 10000; i++ {
+				key := c*10000 + i
+				m.Set(key, i)
+				m.Get(key)
+			}
+		}(c)
+	}
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if i%10 == 0 {
+				m.Reset()
+			} else {
+				m.Clear()
+			}
+		}
+	}()
+	wg.Wait()
+
+	m.Clear()
+	if m.Len() != 0 {
+		t.Errorf("m.Len() != 0: %v", m.Len())
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+}
+
+func TestShrink(t *testing.T) {
+	m := NewWithArgs(16)
+	for i := 0; i < 10000; i++ {
+		m.Set(i, i)
+	}
+	for i := 100; i < 10000; i++ {
+		m.Unset(i)
+	}
+
+	if err := m.ShrinkTo(64); err != NoSpaceLeft {
+		t.Errorf(`An expected "NoSpaceLeft" error, but got: %v`, err)
+	}
+	if err := m.ShrinkTo(200); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+	if m.size() != 256 {
+		t.Errorf("m.size() != 256: %v", m.size())
+	}
+	if m.Len() != 100 {
+		t.Errorf("m.Len() != 100: %v", m.Len())
+	}
+	for i := 0; i < 100; i++ {
+		if v, err := m.Get(i); err != nil || v != i {
+			t.Errorf("m.Get(%v): %v %v", i, v, err)
+		}
+	}
+	if _, err := m.Get(100); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+}
+
+func TestAutoShrink(t *testing.T) {
+	m := NewWithArgs(16)
+	m.SetShrinkAtFullness(0.1)
+	for i := 0; i < 10000; i++ {
+		m.Set(i, i)
+	}
+	grownSize := m.size()
+	for i := 10; i < 10000; i++ {
+		m.Unset(i)
+	}
+
+	if m.size() >= grownSize/16 {
+		t.Errorf("The storage was not shrunk: %v -> %v", grownSize, m.size())
+	}
+	if m.size() < 16 {
+		t.Errorf("The storage was shrunk below the initial size: %v", m.size())
+	}
+	for i := 0; i < 10; i++ {
+		if v, err := m.Get(i); err != nil || v != i {
+			t.Errorf("m.Get(%v): %v %v", i, v, err)
+		}
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+}
+
+func TestSetShrinkAtFullnessInvalid(t *testing.T) {
+	m := NewWithArgs(16)
+	for _, fullness := range []float64{-0.1, math.NaN(), m.growAtFullness, 1} {
+		if err := m.SetShrinkAtFullness(fullness); !errors.Is(err, InvalidArgument) {
+			t.Errorf("m.SetShrinkAtFullness(%v): an expected InvalidArgument error, but got: %v", fullness, err)
+		}
+	}
+	if m.shrinkAtFullness != 0 {
+		t.Errorf("m.shrinkAtFullness == %v", m.shrinkAtFullness)
+	}
+	if err := m.SetShrinkAtFullness(0); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+}
+
+// longestChain returns the length of the longest sequence of occupied slots
+// (keys and tombstones): a lookup of a missing key never checks more slots
+// than that.
+func longestChain[E any](stor *storage[E]) (result uint64) {
+	chain := uint64(0)
+	for idx := uint64(0); idx < 2*stor.size(); idx++ { // twice to count the chain wrapped around the end
+		if stor.items[idx%stor.size()].IsSet() == isSet_notSet {
+			chain = 0
+			continue
+		}
+		chain++
+		if chain > result {
+			result = chain
+		}
+	}
+	return
+}
+
+func TestTombstones(t *testing.T) {
+	m := NewWithArgs(1024)
+
+	liveKeys := 100
+	for i := 0; i < 2000000; i++ {
+		m.Set(i, i)
+		if i >= liveKeys {
+			if err := m.Unset(i - liveKeys); err != nil {
+				t.Fatalf("m.Unset(%v): %v", i-liveKeys, err)
+			}
+		}
+	}
+
+	if m.Len() != liveKeys {
+		t.Errorf("m.Len() != %v: %v", liveKeys, m.Len())
+	}
+	if m.size() != 1024 {
+		t.Errorf("m.size() != 1024: %v", m.size())
+	}
+	if occupied := m.occupiedSlots(); float64(occupied)/float64(m.size()) >= m.growAtFullness {
+		t.Errorf("Too many occupied slots: %v (tombstones: %v)", occupied, m.removedSlots)
+	}
+	if chain := longestChain(m.loadSettledStorage()); chain >= m.size()/4 {
+		t.Errorf("Too long chain of occupied slots: %v", chain)
+	}
+	for i := 2000000 - liveKeys; i < 2000000; i++ {
+		if v, err := m.Get(i); err != nil || v != i {
+			t.Errorf("m.Get(%v): %v %v", i, v, err)
+		}
+	}
+	if _, err := m.Get(0); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+}
+
+func TestTombstonesForbiddenToGrow(t *testing.T) {
+	m := NewWithArgs(64)
+	m.SetForbidGrowing(true)
+
+	for i := 0; i < 100000; i++ {
+		if err := m.Set(i, i); err != nil {
+			t.Fatalf("m.Set(%v): %v", i, err)
+		}
+		if i >= 10 {
+			m.Unset(i - 10)
+		}
+	}
+	if m.Len() != 10 || m.size() != 64 {
+		t.Errorf("m.Len() == %v, m.size() == %v", m.Len(), m.size())
+	}
+}
+
+func TestTombstonesMixedKeyTypes(t *testing.T) {
+	m := NewWithArgs(64)
+
+	for i := 0; i < 40; i++ {
+		m.Set(i, i)
+	}
+	for i := 0; i < 40; i++ {
+		if err := m.Unset(i); err != nil {
+			t.Fatalf("m.Unset(%v): %v", i, err)
+		}
+	}
+
+	// Long strings are not full keys, they reuse the tombstones of int keys
+	for i := 0; i < 40; i++ {
+		m.Set(strings.Repeat("y", 16)+strconv.Itoa(i), i)
+	}
+	for i := 0; i < 40; i++ {
+		key := strings.Repeat("y", 16) + strconv.Itoa(i)
+		if v, err := m.Get(key); err != nil || v != i {
+			t.Errorf("m.Get(%v): %v %v", key, v, err)
+		}
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+}
+
+func TestTombstonesConcurrency(t *testing.T) {
+	m := NewWithArgs(64)
+	m.SetForbidGrowing(true)
+
+	keyAmount := 8
+	concurrency := 8
+	iterations := 20000
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for c := 0; c < concurrency; c++ {
+		go func(c int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				key := (i + c) % keyAmount
+				if err := m.Set(key, c); err != nil {
+					t.Errorf("m.Set(%v): %v", key, err)
+					return
+				}
+				m.Unset((key + 1) % keyAmount)
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	// A key added twice (into different tombstones) would be counted twice
+	for key := 0; key < keyAmount; key++ {
+		m.Set(key, key)
+	}
+	if m.Len() != keyAmount {
+		t.Errorf("m.Len() is not %v: %v", keyAmount, m.Len())
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+}
+
+func TestIncrementalResize(t *testing.T) {
+	m := NewWithArgs(1024)
+
+	keyAmount := 0
+	for m.loadStorage().loadNext() == nil {
+		m.Set(keyAmount, keyAmount)
+		keyAmount++
+	}
+	oldStorage := m.loadStorage()
+	if oldStorage.migratedCount >= oldStorage.size() {
+		t.Errorf("The whole storage was moved at once")
+	}
+	if m.size() != 2048 {
+		t.Errorf("m.size() != 2048: %v", m.size())
+	}
+
+	// Modifying the keys during the migration
+	m.Set(0, -1)
+	m.Unset(1)
+	m.Set(keyAmount, keyAmount)
+	for i := 0; i <= keyAmount; i++ {
+		v, err := m.Get(i)
+		switch i {
+		case 0:
+			if v != -1 {
+				t.Errorf("m.Get(0): %v %v", v, err)
+			}
+		case 1:
+			if err != NotFound {
+				t.Errorf(`An expected "NotFound" error, but got: %v %v`, v, err)
+			}
+		default:
+			if err != nil || v != i {
+				t.Errorf("m.Get(%v): %v %v", i, v, err)
+			}
+		}
+	}
+	if m.Len() != keyAmount {
+		t.Errorf("m.Len() != %v: %v", keyAmount, m.Len())
+	}
+
+	for i := 0; m.loadStorage().loadNext() != nil; i++ {
+		m.Set(-i-1, i)
+	}
+	if m.loadStorage() == oldStorage {
+		t.Errorf("The new storage didn't become the current one")
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+}
+
+func TestHasKey(t *testing.T) {
+	m := NewWithArgs(1024)
+
+	keyAmount := 0
+	for m.loadStorage().loadNext() == nil {
+		m.Set(keyAmount, keyAmount)
+		keyAmount++
+	}
+	m.Unset(1)
+
+	// The migration is in progress: some keys are in the old storage and
+	// some are in the new one
+	for i := -keyAmount; i < 2*keyAmount; i++ {
+		expected := i >= 0 && i < keyAmount && i != 1
+		if m.HasKey(i) != expected {
+			t.Errorf("m.HasKey(%v) != %v", i, expected)
+		}
+	}
+	if m.HasKey("0") {
+		t.Errorf(`m.HasKey("0") should be false`)
+	}
+
+	// An absent key should not be found by another key in its slot
+	m = NewWithArgs(16)
+	keyA, keyB := collidingIntKeys(m)
+	m.Set(keyA, keyA)
+	if !m.HasKey(keyA) {
+		t.Errorf("m.HasKey(%v) == false", keyA)
+	}
+	if m.HasKey(keyB) {
+		t.Errorf("m.HasKey(%v) should be false", keyB)
+	}
+}
+
+func TestCheckConsistencyUnlocks(t *testing.T) {
+	m := NewWithArgs(16)
+	m.Set(1, 1)
+
+	m.busySlots++
+	if err := m.CheckConsistency(); err == nil {
+		t.Errorf("An expected error for the wrong count, but got nil")
+	}
+	m.busySlots--
+
+	// The map should not be left locked, otherwise Clear would hang
+	m.Clear()
+	if m.Len() != 0 {
+		t.Errorf("m.Len() != 0: %v", m.Len())
+	}
+}
+
+func TestIncrementalResizeConcurrency(t *testing.T) {
+	m := NewWithArgs(16)
+
+	keyAmount := 1000
+	for i := 0; i < keyAmount; i++ {
+		m.Set(i, i)
+	}
+
+	concurrency := 8
+	var wg sync.WaitGroup
+	wg.Add(2 * concurrency)
+	for c := 0; c < concurrency; c++ {
+		go func(c int) {
+			defer wg.Done()
+			for i := 0; i < 20000; i++ {
+				m.Set(-c*20000-i-1, i)
+			}
+		}(c)
+		go func(c int) {
+			defer wg.Done()
+			for i := 0; i < 20000; i++ {
+				key := (c*20000 + i) % keyAmount
+				if v, err := m.Get(key); err != nil || v != key {
+					t.Errorf("m.Get(%v): %v %v", key, v, err)
+					return
+				}
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if m.Len() != keyAmount+concurrency*20000 {
+		t.Errorf("m.Len() != %v: %v", keyAmount+concurrency*20000, m.Len())
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+}
+
+// TestGetDuringResize checks that a Get racing with resizes of the map (in
+// any direction) never returns a spurious NotFound or a value which was
+// never set.
+func TestGetDuringResize(t *testing.T) {
+	m := NewWithArgs(16)
+	m.SetShrinkAtFullness(0.1)
+
+	stableKeyAmount := 1000
+	updatedKeyAmount := 100
+	for i := 0; i < stableKeyAmount+updatedKeyAmount; i++ {
+		m.Set(i, i)
+	}
+
+	var isDone int32
+	var churnWG, backgroundWG sync.WaitGroup
+
+	// Updating the values of some keys
+	backgroundWG.Add(1)
+	go func() {
+		defer backgroundWG.Done()
+		for round := 0; atomic.LoadInt32(&isDone) == 0; round++ {
+			for i := stableKeyAmount; i < stableKeyAmount+updatedKeyAmount; i++ {
+				if round%2 == 0 {
+					m.Set(i, -i)
+				} else {
+					m.Set(i, i)
+				}
+			}
+		}
+	}()
+
+	readersConcurrency := 8
+	storagesSeen := make([]map[*storage[mapEntry]]bool, readersConcurrency)
+	backgroundWG.Add(readersConcurrency)
+	for c := 0; c < readersConcurrency; c++ {
+		storagesSeen[c] = map[*storage[mapEntry]]bool{}
+		go func(c int) {
+			defer backgroundWG.Done()
+			for atomic.LoadInt32(&isDone) == 0 {
+				for i := 0; i < stableKeyAmount+updatedKeyAmount; i++ {
+					storagesSeen[c][m.loadStorage()] = true
+					v, err := m.Get(i)
+					if err != nil || (v != i && v != -i) {
+						t.Errorf("m.Get(%v): %v %v", i, v, err)
+						return
+					}
+				}
+			}
+		}(c)
+	}
+
+	// Growing and shrinking the map
+	churnConcurrency := 4
+	churnWG.Add(churnConcurrency)
+	for c := 0; c < churnConcurrency; c++ {
+		go func(c int) {
+			defer churnWG.Done()
+			for round := 0; round < 5; round++ {
+				for i := 0; i < 5000; i++ {
+					m.Set(-c*5000-i-1, i)
+				}
+				for i := 0; i < 5000; i++ {
+					m.Unset(-c*5000 - i - 1)
+				}
+			}
+		}(c)
+	}
+
+	churnWG.Wait()
+	atomic.StoreInt32(&isDone, 1)
+	backgroundWG.Wait()
+
+	allStoragesSeen := map[*storage[mapEntry]]bool{}
+	for _, seen := range storagesSeen {
+		for stor := range seen {
+			allStoragesSeen[stor] = true
+		}
+	}
+	if len(allStoragesSeen) < 2 {
+		t.Errorf("The map was not resized during the reading")
+	}
+	if m.Len() != stableKeyAmount+updatedKeyAmount {
+		t.Errorf("m.Len() != %v: %v", stableKeyAmount+updatedKeyAmount, m.Len())
+	}
+}
+
+type testLogger struct {
+	messages []string
+}
+
+func (l *testLogger) Printf(format string, args ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func TestNewWithOptions(t *testing.T) {
+	logger := &testLogger{}
+	m0, err := NewWithOptions(WithBlockSize(10), WithLoadFactor(0.5), WithGrowthFactor(4), WithMaxSize(64), WithLogger(logger))
+	if err != nil {
+		t.Fatalf("Cannot create the map: %v", err)
+	}
+	m1 := NewWithArgs(16)
+
+	if m0.size() != 16 {
+		t.Errorf("m0.size() != 16: %v", m0.size())
+	}
+	if len(logger.messages) != 1 {
+		t.Errorf("Expected one message about the block size, got: %v", logger.messages)
+	}
+
+	// The load factor is 0.5, so the storage is grown when the half of it is
+	// occupied; and it's grown 4 times.
+	for i := 0; i < 8; i++ {
+		if err := m0.Set(i, i); err != nil {
+			t.Fatalf("Cannot m0.Set(%v): %v", i, err)
+		}
+		if err := m1.Set(i, i); err != nil {
+			t.Fatalf("Cannot m1.Set(%v): %v", i, err)
+		}
+	}
+	if m0.size() != 64 {
+		t.Errorf("m0.size() != 64: %v", m0.size())
+	}
+	if m1.size() != 16 {
+		t.Errorf("m1.size() != 16: %v", m1.size())
+	}
+
+	// The maximal size is 64, so there's a room for only 31 keys
+	var i int
+	for i = 8; i < 64; i++ {
+		if err := m0.Set(i, i); err != nil {
+			if err != NoSpaceLeft {
+				t.Errorf(`An expected "NoSpaceLeft" error, but got: %v`, err)
+			}
+			break
+		}
+		if err := m1.Set(i, i); err != nil {
+			t.Fatalf("Cannot m1.Set(%v): %v", i, err)
+		}
+	}
+	if i != 31 || m0.Len() != 31 {
+		t.Errorf("Unexpected amount of keys fit into m0: %v %v", i, m0.Len())
+	}
+	if err := m0.CheckConsistency(); err != nil {
+		t.Error(err)
+	}
+
+	m2, err := NewWithOptions(WithThreadSafety(false), WithForbidGrowing(true))
+	if err != nil {
+		t.Fatalf("Cannot create the map: %v", err)
+	}
+	if m2.threadSafety || !m2.IsForbiddenToGrow() || m2.size() != defaultBlockSize {
+		t.Errorf("The options are not applied: %v %v %v", m2.threadSafety, m2.IsForbiddenToGrow(), m2.size())
+	}
+	if !m1.threadSafety || m1.IsForbiddenToGrow() {
+		t.Errorf("The options of m2 affected m1")
+	}
+}
+
+func TestNewWithOptionsInvalid(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithBlockSize(0)},
+		{WithLoadFactor(0)},
+		{WithLoadFactor(1)},
+		{WithLoadFactor(math.NaN())},
+		{WithGrowthFactor(1)},
+		{WithGrowthFactor(3)},
+		{WithMaxSize(0)},
+		{WithMaxSize(100)},
+		{WithMaxSize(1 << 33)},
+		{WithBlockSize(64), WithMaxSize(32)},
+		{WithBlockSize(maximalSize + 1)},
+		{WithBlockSize(math.MaxUint64)},
+		{WithShrinkAtFullness(-0.1)},
+		{WithShrinkAtFullness(math.NaN())},
+		{WithShrinkAtFullness(0.85)},
+		{WithShrinkAtFullness(0.3), WithLoadFactor(0.3)},
+		{WithLoadFactor(0.3), WithShrinkAtFullness(0.3)},
+		{WithHasher(nil)},
+		{WithLogger(nil)},
+	} {
+		m, err := NewWithOptions(opts...)
+		if !errors.Is(err, InvalidOption) || m != nil {
+			t.Errorf(`An expected "InvalidOption" error, but got: %v %v`, m, err)
+		}
+	}
+
+	if _, err := NewTypedWithOptions[int, int](WithHasher(hasher.New())); !errors.Is(err, InvalidOption) {
+		t.Errorf(`An expected "InvalidOption" error, but got: %v`, err)
+	}
+}
+
+func TestWithShrinkAtFullness(t *testing.T) {
+	// The shrink fullness is checked against the final load factor, so the
+	// order of the options doesn't matter.
+	m, err := NewWithOptions(WithBlockSize(16), WithShrinkAtFullness(0.86), WithLoadFactor(0.9))
+	if err != nil {
+		t.Fatalf("Cannot create the map: %v", err)
+	}
+	for i := 0; i < 10000; i++ {
+		m.Set(i, i)
+	}
+	grownSize := m.size()
+	for i := 10; i < 10000; i++ {
+		m.Unset(i)
+	}
+	if m.size() >= grownSize/16 {
+		t.Errorf("The storage was not shrunk: %v -> %v", grownSize, m.size())
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Error(err)
+	}
+
+	if err := m.SetShrinkAtFullness(0.9); !errors.Is(err, InvalidArgument) {
+		t.Errorf("m.SetShrinkAtFullness(0.9): an expected InvalidArgument error, but got: %v", err)
+	}
+	if err := m.SetShrinkAtFullness(0.88); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+}
+
+// collidingHasher is the default hasher, but all the hash values are the same
+type collidingHasher struct {
+	*hasher.Hasher
+	calls int64
+}
+
+func (h *collidingHasher) CompleteHash(keyPreHash uint64, keyTypeID uint8) uint64 {
+	atomic.AddInt64(&h.calls, 1)
+	return 0
+}
+
+func (h *collidingHasher) Hash(key interface{}) uint64 {
+	atomic.AddInt64(&h.calls, 1)
+	return 0
+}
+
+func TestCustomHasher(t *testing.T) {
+	h := &collidingHasher{Hasher: hasher.New()}
+	m, err := NewWithOptions(WithBlockSize(16), WithHasher(h))
+	if err != nil {
+		t.Fatalf("Cannot create the map: %v", err)
+	}
+
+	for i := 0; i < 100; i++ {
+		if err := m.Set(i, i); err != nil {
+			t.Fatalf("m.Set(%v): %v", i, err)
+		}
+		if err := m.Set(fmt.Sprintf("long string key #%v", i), i); err != nil {
+			t.Fatalf("m.Set(%v): %v", i, err)
+		}
+	}
+	if atomic.LoadInt64(&h.calls) == 0 {
+		t.Fatalf("The custom hasher was not used")
+	}
+	for stor, i := m.lastStorage(), uint64(0); i < stor.size(); i++ {
+		if slot := &stor.items[i]; slot.IsSet() == isSet_set && slot.hashValue != 0 {
+			t.Fatalf("The key %v was hashed not by the custom hasher", slot.entry.key)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		if v, err := m.Get(i); err != nil || v != i {
+			t.Errorf("m.Get(%v): %v %v", i, v, err)
+		}
+		if v, err := m.Get(fmt.Sprintf("long string key #%v", i)); err != nil || v != i {
+			t.Errorf("m.Get(%q): %v %v", fmt.Sprintf("long string key #%v", i), v, err)
+		}
+	}
+	if !m.HasKey(0) {
+		t.Errorf("m.HasKey(0) == false")
+	}
+	for i := 0; i < 100; i += 2 {
+		if err := m.Unset(i); err != nil {
+			t.Errorf("m.Unset(%v): %v", i, err)
+		}
+	}
+	if m.Len() != 150 {
+		t.Errorf("m.Len() != 150: %v", m.Len())
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Error(err)
+	}
+}
+
+// countingHasher is the default hasher with other hash values, so a key
+// hashed by a hasher other than the map's one is not found.
+type countingHasher struct {
+	*hasher.Hasher
+	calls int64
+}
+
+func (h *countingHasher) CompleteHash(keyPreHash uint64, keyTypeID uint8) uint64 {
+	atomic.AddInt64(&h.calls, 1)
+	return ^h.Hasher.CompleteHash(keyPreHash, keyTypeID)
+}
+
+func (h *countingHasher) Hash(key interface{}) uint64 {
+	atomic.AddInt64(&h.calls, 1)
+	return ^h.Hasher.Hash(key)
+}
+
+func TestCustomHasherAllPaths(t *testing.T) {
+	h := &countingHasher{Hasher: hasher.New()}
+	m, err := NewWithOptions(WithBlockSize(16), WithHasher(h))
+	if err != nil {
+		t.Fatalf("Cannot create the map: %v", err)
+	}
+
+	expectValue := func(expected interface{}) func(interface{}, error) error {
+		return func(v interface{}, err error) error {
+			if err != nil || v != expected {
+				return fmt.Errorf("got %v %v instead of %v", v, err, expected)
+			}
+			return nil
+		}
+	}
+	for _, testCase := range []struct {
+		name string
+		fn   func() error
+	}{
+		{"Set", func() error { return m.Set(1, "a") }},
+		{"Get", func() error { return expectValue("a")(m.Get(1)) }},
+		{"HasKey", func() error {
+			if !m.HasKey(1) {
+				return fmt.Errorf("m.HasKey(1) == false")
+			}
+			return nil
+		}},
+		{"Swap", func() error {
+			_, err := m.Swap(1, "b")
+			return err
+		}},
+		{"GetOrSet", func() error {
+			if v, loaded, err := m.GetOrSet(1, "c"); err != nil || !loaded || v != "b" {
+				return fmt.Errorf("m.GetOrSet(1): %v %v %v", v, loaded, err)
+			}
+			return nil
+		}},
+		{"Update", func() error {
+			return m.Update(1, func(oldValue interface{}, exists bool) (interface{}, bool) {
+				return "d", exists
+			})
+		}},
+		{"CompareAndSwap", func() error {
+			if swapped, err := m.CompareAndSwap(1, "d", "e"); err != nil || !swapped {
+				return fmt.Errorf("m.CompareAndSwap(1): %v %v", swapped, err)
+			}
+			return nil
+		}},
+		{"AddUint64", func() error {
+			_, err := m.AddUint64(2, 1)
+			return err
+		}},
+		{"SetMany", func() error { return errors.Join(m.SetMany([]Key{3, 4}, []interface{}{3, 4})...) }},
+		{"GetMany", func() error {
+			values, errs := m.GetMany([]Key{3, 4})
+			if !reflect.DeepEqual(values, []interface{}{3, 4}) {
+				return fmt.Errorf("m.GetMany(): %v", values)
+			}
+			return errors.Join(errs...)
+		}},
+		{"UnsetMany", func() error { return errors.Join(m.UnsetMany([]Key{3, 4})...) }},
+		{"SetBytesByBytes", func() error { return m.SetBytesByBytes([]byte("bytes"), []byte("value")) }},
+		{"GetByBytes", func() error {
+			if v, err := m.GetByBytes([]byte("bytes")); err != nil || string(v.([]byte)) != "value" {
+				return fmt.Errorf("got %v %v instead of %v", v, err, "value")
+			}
+			return nil
+		}},
+		{"SetUint64", func() error { return m.Set(uint64(7), 7) }},
+		{"GetByUint64", func() error { return expectValue(7)(m.GetByUint64(7)) }},
+		{"SetByUintptrUsingFunc", func() error {
+			return m.SetByUintptrUsingFunc(8, func(v *interface{}) { *v = 8 })
+		}},
+		{"GetByUintptr", func() error { return expectValue(8)(m.GetByUintptr(8)) }},
+		{"SetByString", func() error { return m.SetByString("key", 5) }},
+		{"GetByString", func() error { return expectValue(5)(m.GetByString("key")) }},
+		{"SwapByString", func() error {
+			_, err := m.SwapByString("key", 6)
+			return err
+		}},
+		{"SetByBytes", func() error { return m.SetByBytes([]byte("another bytes"), 9) }},
+		{"GetByBytes(SetByBytes)", func() error { return expectValue(9)(m.GetByBytes([]byte("another bytes"))) }},
+		{"SetByUint64", func() error { return m.SetByUint64(10, 10) }},
+		{"GetByUint64(SetByUint64)", func() error { return expectValue(10)(m.GetByUint64(10)) }},
+		{"SetByUintptr", func() error { return m.SetByUintptr(11, 11) }},
+		{"GetByUintptr(SetByUintptr)", func() error { return expectValue(11)(m.GetByUintptr(11)) }},
+		{"UnsetByString", func() error { return m.UnsetByString("key") }},
+		{"UnsetByBytes", func() error { return m.UnsetByBytes([]byte("another bytes")) }},
+		{"UnsetByUint64", func() error { return m.UnsetByUint64(10) }},
+		{"UnsetByUintptr", func() error { return m.UnsetByUintptr(11) }},
+		{"Unset", func() error { return m.Unset(1) }},
+	} {
+		calls := atomic.LoadInt64(&h.calls)
+		if err := testCase.fn(); err != nil {
+			t.Errorf("%v: %v", testCase.name, err)
+		}
+		if atomic.LoadInt64(&h.calls) == calls {
+			t.Errorf("%v: the custom hasher was not used", testCase.name)
+		}
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReenableGrowing(t *testing.T) {
+	m := NewWithArgs(16)
+	m.SetForbidGrowing(true)
+
+	var i int
+	for i = 0; i < 16; i++ {
+		if err := m.Set(i, i); err != nil {
+			if err != ForbiddenToGrow {
+				t.Errorf(`An expected "ForbiddenToGrow" error, but got: %v`, err)
+			}
+			break
+		}
+	}
+	if i == 16 || m.size() != 16 {
+		t.Fatalf("The storage has grown while it's forbidden: %v %v", i, m.size())
+	}
+
+	// The deferred growing should be done right away
+	m.SetForbidGrowing(false)
+	if m.size() != 32 {
+		t.Errorf("The deferred growing was not done: %v", m.size())
+	}
+	for ; i < 1024; i++ {
+		if err := m.Set(i, i); err != nil {
+			t.Fatalf("m.Set(%v): %v", i, err)
+		}
+	}
+
+	m.SetForbidGrowing(true)
+	m.SetForbidGrowing(false)
+	if m.size() != 2048 {
+		t.Errorf("The storage was resized without a reason: %v", m.size())
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestForbidGrowingConcurrency(t *testing.T) {
+	m := NewWithArgs(16)
+
+	var isDone int32
+	var writersWG, togglerWG sync.WaitGroup
+
+	togglerWG.Add(1)
+	go func() {
+		defer togglerWG.Done()
+		for atomic.LoadInt32(&isDone) == 0 {
+			m.SetForbidGrowing(true)
+			size := m.size()
+			for i := 0; i < 100; i++ {
+				if newSize := m.size(); newSize > size {
+					t.Errorf("The storage has grown while it's forbidden: %v -> %v", size, newSize)
+				}
+			}
+			m.SetForbidGrowing(false)
+		}
+	}()
+
+	concurrency := 8
+	keyAmount := 4096
+	writersWG.Add(concurrency)
+	for c := 0; c < concurrency; c++ {
+		go func(c int) {
+			defer writersWG.Done()
+			for i := 0; i < keyAmount; i++ {
+				for {
+					err := m.Set(c*keyAmount+i, i)
+					if err == nil {
+						break
+					}
+					if err != ForbiddenToGrow {
+						t.Errorf("m.Set(%v): %v", c*keyAmount+i, err)
+						return
+					}
+				}
+			}
+		}(c)
+	}
+
+	writersWG.Wait()
+	atomic.StoreInt32(&isDone, 1)
+	togglerWG.Wait()
+
+	if m.Len() != concurrency*keyAmount {
+		t.Errorf("m.Len() != %v: %v", concurrency*keyAmount, m.Len())
+	}
+	for i := 0; i < concurrency*keyAmount; i++ {
+		if v, err := m.Get(i); err != nil || v != i%keyAmount {
+			t.Fatalf("m.Get(%v): %v %v", i, v, err)
+		}
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLookupHash(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithHasher(&countingHasher{Hasher: hasher.New()})}} {
+		m, err := NewWithOptions(opts...)
+		if err != nil {
+			t.Fatalf("Cannot create the map: %v", err)
+		}
+		expected := func(preHashValue uint64, typeID uint8, preHashValueIsFull bool) [3]uint64 {
+			hashValue := m.completeHash(preHashValue, typeID)
+			if !preHashValueIsFull {
+				preHashValue, typeID = 0, 0
+			}
+			return [3]uint64{preHashValue, uint64(typeID), hashValue}
+		}
+		actual := func(fastKey uint64, fastKeyType uint8, hashValue uint64) [3]uint64 {
+			return [3]uint64{fastKey, uint64(fastKeyType), hashValue}
+		}
+		for _, key := range []Key{1, "a short key", "a long string key, longer than 8 bytes", 1.5} {
+			fastKey, fastKeyType, hashValue, err := m.hashKey(key)
+			if err != nil {
+				t.Fatalf("m.hashKey(%v): %v", key, err)
+			}
+			if e, a := expected(m.preHash(key)), actual(fastKey, fastKeyType, hashValue); e != a {
+				t.Errorf("m.hashKey(%v): %v != %v", key, a, e)
+			}
+		}
+		for _, key := range [][]byte{[]byte("short"), []byte("a long bytes key, longer than 8 bytes")} {
+			if e, a := expected(m.preHashBytes(key)), actual(m.hashBytes(key)); e != a {
+				t.Errorf("m.hashBytes(%q): %v != %v", key, a, e)
+			}
+		}
+		if e, a := expected(m.preHashUint64(7)), actual(m.hashUint64(7)); e != a {
+			t.Errorf("m.hashUint64(7): %v != %v", a, e)
+		}
+		if e, a := expected(m.preHashUintptr(7)), actual(m.hashUintptr(7)); e != a {
+			t.Errorf("m.hashUintptr(7): %v != %v", a, e)
+		}
+	}
+}
+
+func BenchmarkGetByUint64Hasher(b *testing.B) {
+	for _, testCase := range []struct {
+		name string
+		opts []Option
+	}{
+		{"default", nil},
+		{"custom", []Option{WithHasher(hasher.New())}},
+	} {
+		b.Run(testCase.name, func(b *testing.B) {
+			m, err := NewWithOptions(append(testCase.opts, WithBlockSize(1<<12))...)
+			if err != nil {
+				b.Fatalf("Cannot create the map: %v", err)
+			}
+			for i := uint64(0); i < 1<<10; i++ {
+				m.Set(i, i)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.GetByUint64(uint64(i) & (1<<10 - 1))
+			}
+		})
+	}
+}
+
+func TestRandomSeed(t *testing.T) {
+	for _, opt := range []Option{WithRandomSeed(), WithProcessSeed()} {
+		m, err := NewWithOptions(WithBlockSize(1024), opt)
+		if err != nil {
+			t.Fatalf("Cannot create the map: %v", err)
+		}
+		mDefault := NewWithArgs(1024)
+
+		// These keys collide with the default hasher
+		for i := uint64(0); i < 500; i++ {
+			m.Set(i<<32|i, i)
+			mDefault.Set(i<<32|i, i)
+			m.Set(fmt.Sprintf("long string key #%v", i), i)
+		}
+		if chain := longestChain(m.lastStorage()); chain > 100 {
+			t.Errorf("Too long chain with a random seed: %v", chain)
+		}
+		if chain := longestChain(mDefault.lastStorage()); chain < 500 {
+			t.Logf("The keys don't collide with the default hasher anymore: %v", chain)
+		}
+
+		for i := uint64(0); i < 500; i++ {
+			if v, err := m.Get(i<<32 | i); err != nil || v != i {
+				t.Errorf("m.Get(%v): %v %v", i<<32|i, v, err)
+			}
+			if v, err := m.Get(fmt.Sprintf("long string key #%v", i)); err != nil || v != i {
+				t.Errorf("m.Get(%q): %v %v", fmt.Sprintf("long string key #%v", i), v, err)
+			}
+		}
+		if err := m.CheckConsistency(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// pairKey is a user-defined key. Without Hash() and Equal() the keys
+// pairKey{"a b", "c"} and pairKey{"a", "b c"} would be considered equal,
+// since they are formatted the same way.
+type pairKey struct {
+	A, B string
+}
+
+func (k pairKey) Hash() uint64 {
+	hashA, _, _ := hasher.PreHashString(k.A)
+	hashB, _, _ := hasher.PreHashString(k.B)
+	return hashA ^ hashB*31
+}
+
+func (k pairKey) Equal(other Key) bool {
+	otherPair, ok := other.(pairKey)
+	return ok && k == otherPair
+}
+
+func TestHashableKey(t *testing.T) {
+	m := NewWithArgs(16)
+
+	key0 := pairKey{"a b", "c"}
+	key1 := pairKey{"a", "b c"}
+	if fmt.Sprintf("%v", key0) != fmt.Sprintf("%v", key1) {
+		t.Fatalf("The keys are expected to be formatted the same way")
+	}
+	m.Set(key0, 0)
+	m.Set(key1, 1)
+	m.Set("{a b c}", 2)
+	if m.Len() != 3 {
+		t.Errorf("m.Len() != 3: %v", m.Len())
+	}
+	if v, err := m.Get(pairKey{"a b", "c"}); err != nil || v != 0 {
+		t.Errorf("m.Get(key0): %v %v", v, err)
+	}
+	if v, err := m.Get(key1); err != nil || v != 1 {
+		t.Errorf("m.Get(key1): %v %v", v, err)
+	}
+	if err := m.Unset(key0); err != nil {
+		t.Errorf("m.Unset(key0): %v", err)
+	}
+	if _, err := m.Get(key0); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+	if v, err := m.Get(key1); err != nil || v != 1 {
+		t.Errorf("m.Get(key1): %v %v", v, err)
+	}
+
+	// Hash() is used instead of fmt.Sprintf, so there are no allocations
+	var key Key = key1
+	if allocs := testing.AllocsPerRun(100, func() { m.Get(key) }); allocs != 0 {
+		t.Errorf("m.Get(key1) allocates: %v", allocs)
+	}
+
+	if err := m.CheckConsistency(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestShortStringKeys(t *testing.T) {
+	m := NewWithArgs(16)
+	keys := []string{"", "a", "a\x00", "\x00a", "\xff", "\xfe", "\xff\xff", "é", "abcdefg", "abcdefgh"}
+	for i, key := range keys {
+		m.Set(key, i)
+		m.SetBytesByBytes([]byte(key), []byte{byte(i)})
+	}
+	if m.Len() != 2*len(keys) {
+		t.Errorf("m.Len() != %v: %v", 2*len(keys), m.Len())
+	}
+	for i, key := range keys {
+		if v, err := m.Get(key); err != nil || v != i {
+			t.Errorf("m.Get(%q): %v %v", key, v, err)
+		}
+	}
+}
+
+type myInt int
+
+func TestNamedTypeKeys(t *testing.T) {
+	// The keys of different types are different keys, even if the values
+	// and the kinds are the same (as in the built-in maps)
+	m := NewWithArgs(16)
+	m.Set(5, "int")
+	m.Set(myInt(5), "myInt")
+	m.Set(int64(5), "int64")
+	if m.Len() != 3 {
+		t.Errorf("m.Len() != 3: %v", m.Len())
+	}
+	for key, expected := range map[Key]string{5: "int", myInt(5): "myInt", int64(5): "int64"} {
+		if v, err := m.Get(key); err != nil || v != expected {
+			t.Errorf("m.Get(%T(%v)): %v %v", key, key, v, err)
+		}
+	}
+}
+
+// foreignKey imitates a key type of another package, which is registered by
+// hasher.RegisterKeyType (its values are equal if the IDs are the same).
+type foreignKey struct {
+	ID       int
+	callback func()
+}
+
+var _, foreignKeyRegisterErr = hasher.RegisterKeyType(
+	reflect.TypeOf(foreignKey{}),
+	func(key interface{}) uint64 { return uint64(key.(foreignKey).ID) },
+	func(keyA, keyB interface{}) bool { return keyA.(foreignKey).ID == keyB.(foreignKey).ID },
+)
+
+func TestRegisteredKeyType(t *testing.T) {
+	if foreignKeyRegisterErr != nil {
+		t.Fatalf("Cannot register the key type: %v", foreignKeyRegisterErr)
+	}
+
+	m := NewWithArgs(16)
+	for i := 0; i < 100; i++ {
+		m.Set(foreignKey{ID: i, callback: func() {}}, i)
+	}
+	for i := 0; i < 100; i++ {
+		if v, err := m.Get(foreignKey{ID: i}); err != nil || v != i {
+			t.Errorf("m.Get(%v): %v %v", i, v, err)
+		}
+	}
+	if err := m.Unset(foreignKey{ID: 0}); err != nil {
+		t.Errorf("m.Unset(0): %v", err)
+	}
+	if m.Len() != 99 {
+		t.Errorf("m.Len() != 99: %v", m.Len())
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Error(err)
+	}
+}
+
+// floatTestKeys are the float and complex keys which are handled specially
+// by the float key policies (zeroes of both signs and NaN-s).
+func floatTestKeys() []Key {
+	negZero := math.Copysign(0, -1)
+	nan := math.NaN()
+	otherNaN := math.Float64frombits(math.Float64bits(nan) | 1)
+	return []Key{
+		0.0, negZero, nan, nan, otherNaN, 1.5, math.Inf(1), math.Inf(-1),
+		float32(0), float32(negZero), float32(nan), float32(nan), float32(1.5),
+		complex(0, 0), complex(negZero, 0), complex(0, negZero), complex(nan, 0), complex(1.5, negZero),
+		complex64(complex(0, 0)), complex64(complex(negZero, negZero)), complex64(complex(0, nan)),
+	}
+}
+
+func TestFloatKeysGoCompatible(t *testing.T) {
+	m := NewWithArgs(16)
+	stdMap := map[interface{}]int{}
+	keys := floatTestKeys()
+	for i, key := range keys {
+		stdMap[key] = i
+		if err := m.Set(key, i); err != nil {
+			t.Fatalf("m.Set(%v): %v", key, err)
+		}
+		if m.Len() != len(stdMap) {
+			t.Fatalf("m.Len() != len(stdMap) after setting %#v: %v != %v", key, m.Len(), len(stdMap))
+		}
+	}
+	for _, key := range keys {
+		stdValue, stdOK := stdMap[key]
+		value, err := m.Get(key)
+		if stdOK != (err == nil) || (stdOK && value != stdValue) {
+			t.Errorf("m.Get(%#v) == %v, %v; but stdMap[%#v] == %v, %v", key, value, err, key, stdValue, stdOK)
+		}
+	}
+	if err := m.Unset(math.NaN()); err != NotFound {
+		t.Errorf("m.Unset(NaN) should return NotFound, got %v", err)
+	}
+	if err := m.Unset(math.Copysign(0, -1)); err != nil {
+		t.Errorf("m.Unset(-0): %v", err)
+	}
+	if _, err := m.Get(0.0); err != NotFound {
+		t.Errorf("+0 should be unset with -0, got %v", err)
+	}
+	if err := m.CheckConsistency(); err != nil {
+		t.Error(err)
+	}
+
+	typed := NewTyped[float64, int]()
+	stdTyped := map[float64]int{}
+	for i, key := range keys {
+		if key, ok := key.(float64); ok {
+			typed.Set(key, i)
+			stdTyped[key] = i
+		}
+	}
+	if typed.Len() != len(stdTyped) {
+		t.Errorf("typed.Len() != len(stdTyped): %v != %v", typed.Len(), len(stdTyped))
+	}
+	for key, stdValue := range stdTyped {
+		if value, err := typed.Get(key); key == key && (err != nil || value != stdValue) {
+			t.Errorf("typed.Get(%v) == %v, %v; but stdTyped[%v] == %v", key, value, err, key, stdValue)
+		}
+	}
+}
+
+// bitwiseKey is the key of the built-in map to check FloatKeysBitwise against.
+type bitwiseKey struct {
+	typ    reflect.Type
+	re, im uint64
+}
+
+func toBitwiseKey(key Key) bitwiseKey {
+	switch key := key.(type) {
+	case float32:
+		return bitwiseKey{typ: reflect.TypeOf(key), re: uint64(math.Float32bits(key))}
+	case float64:
+		return bitwiseKey{typ: reflect.TypeOf(key), re: math.Float64bits(key)}
+	case complex64:
+		return bitwiseKey{typ: reflect.TypeOf(key), re: uint64(math.Float32bits(real(key))), im: uint64(math.Float32bits(imag(key)))}
+	case complex128:
+		return bitwiseKey{typ: reflect.TypeOf(key), re: math.Float64bits(real(key)), im: math.Float64bits(imag(key))}
+	}
+	panic(fmt.Sprintf("unexpected key %#v", key))
+}
+
+func TestFloatKeysBitwise(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithFloatKeyPolicy(hasher.FloatKeysBitwise)},
+		{WithFloatKeyPolicy(hasher.FloatKeysBitwise), WithRandomSeed()},
+	} {
+		m, err := NewWithOptions(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdMap := map[bitwiseKey]int{}
+		keys := floatTestKeys()
+		for i, key := range keys {
+			stdMap[toBitwiseKey(key)] = i
+			if err := m.Set(key, i); err != nil {
+				t.Fatalf("m.Set(%v): %v", key, err)
+			}
+		}
+		if m.Len() != len(stdMap) {
+			t.Errorf("m.Len() != len(stdMap): %v != %v", m.Len(), len(stdMap))
+		}
+		for _, key := range keys {
+			if value, err := m.Get(key); err != nil || value != stdMap[toBitwiseKey(key)] {
+				t.Errorf("m.Get(%#v) == %v, %v; expected %v", key, value, err, stdMap[toBitwiseKey(key)])
+			}
+		}
+		if err := m.CheckConsistency(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, err := NewWithOptions(WithFloatKeyPolicy(hasher.FloatKeysBitwise), WithHasher(&collidingHasher{Hasher: hasher.New()})); !errors.Is(err, InvalidOption) {
+		t.Errorf("a float key policy should not be applicable to a custom hasher, got %v", err)
+	}
+	if _, err := NewTypedWithOptions[float64, int](WithFloatKeyPolicy(hasher.FloatKeysBitwise)); !errors.Is(err, InvalidOption) {
+		t.Errorf("FloatKeysBitwise should not be supported by Typed, got %v", err)
+	}
+}
+
+func TestKeyType(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithKeyType(reflect.TypeOf(""))},
+		{WithKeyType(reflect.TypeOf("")), WithRandomSeed()},
+	} {
+		m, err := NewWithOptions(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.KeyType() != reflect.TypeOf("") {
+			t.Errorf("m.KeyType() != string: %v", m.KeyType())
+		}
+		for i := 0; i < 100; i++ {
+			if err := m.Set(fmt.Sprint(i), i); err != nil {
+				t.Fatalf("m.Set(%v): %v", i, err)
+			}
+		}
+		for i := 0; i < 100; i++ {
+			if value, err := m.Get(fmt.Sprint(i)); err != nil || value != i {
+				t.Errorf("m.Get(%v) == %v, %v", i, value, err)
+			}
+		}
+
+		err = m.Set(5, 5)
+		var wrongKeyTypeErr *WrongKeyTypeError
+		if !errors.Is(err, WrongKeyType) || !errors.As(err, &wrongKeyTypeErr) {
+			t.Fatalf("m.Set(5) should return a WrongKeyTypeError, got %v", err)
+		}
+		if wrongKeyTypeErr.Expected != reflect.TypeOf("") || wrongKeyTypeErr.Actual != reflect.TypeOf(0) {
+			t.Errorf("unexpected types in the error: %v", wrongKeyTypeErr)
+		}
+		for name, err := range map[string]error{
+			"Get":             func() error { _, err := m.Get(5); return err }(),
+			"GetByUint64":     func() error { _, err := m.GetByUint64(5); return err }(),
+			"GetByBytes":      func() error { _, err := m.GetByBytes([]byte("5")); return err }(),
+			"SetBytesByBytes": m.SetBytesByBytes([]byte("5"), nil),
+			"Unset":           m.Unset(5),
+			"Swap":            func() error { _, err := m.Swap(5, 5); return err }(),
+			"GetOrSet":        func() error { _, _, err := m.GetOrSet(5, 5); return err }(),
+			"Update":          m.Update(5, func(interface{}, bool) (interface{}, bool) { return 5, true }),
+			"AddUint64":       func() error { _, err := m.AddUint64(5, 1); return err }(),
+			"CompareAndSwap":  func() error { _, err := m.CompareAndSwap(5, 5, 6); return err }(),
+		} {
+			if !errors.Is(err, WrongKeyType) {
+				t.Errorf("m.%v() should return WrongKeyType, got %v", name, err)
+			}
+		}
+		if m.HasKey(5) {
+			t.Errorf("m.HasKey(5) should be false")
+		}
+
+		errs := m.SetMany([]Key{"a", 1, "b"}, []interface{}{1, 2, 3})
+		if errs == nil || errs[0] != nil || !errors.Is(errs[1], WrongKeyType) || errs[2] != nil {
+			t.Errorf("unexpected errors of m.SetMany(): %v", errs)
+		}
+		values, errs := m.GetMany([]Key{"a", 1, "b"})
+		if errs == nil || errs[0] != nil || !errors.Is(errs[1], WrongKeyType) || errs[2] != nil || values[0] != 1 || values[2] != 3 {
+			t.Errorf("unexpected result of m.GetMany(): %v %v", values, errs)
+		}
+		errs = m.UnsetMany([]Key{"a", 1})
+		if errs == nil || errs[0] != nil || !errors.Is(errs[1], WrongKeyType) {
+			t.Errorf("unexpected errors of m.UnsetMany(): %v", errs)
+		}
+		if m.Len() != 101 {
+			t.Errorf("m.Len() != 101: %v", m.Len())
+		}
+		if err := m.CheckConsistency(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	m, err := NewWithOptions(WithKeyType(reflect.TypeOf(uint64(0))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Set(uint64(1000), "a")
+	if value, err := m.GetByUint64(1000); err != nil || value != "a" {
+		t.Errorf("m.GetByUint64(1000) == %v, %v", value, err)
+	}
+	if _, err := m.Get(1000); !errors.Is(err, WrongKeyType) {
+		t.Errorf("m.Get(int) should return WrongKeyType, got %v", err)
+	}
+
+	m, err = NewWithOptions(WithKeyType(reflect.TypeOf([]byte(nil))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetBytesByBytes([]byte("a key longer than 8 bytes"), []byte("a"))
+	if value, err := m.GetByBytes([]byte("a key longer than 8 bytes")); err != nil || !isEqualValue(value, []byte("a")) {
+		t.Errorf("m.GetByBytes() == %v, %v", value, err)
+	}
+
+	for _, opts := range [][]Option{
+		{WithKeyType(nil)},
+		{WithKeyType(reflect.TypeOf(struct{}{}))},
+		{WithKeyType(reflect.TypeOf("")), WithHasher(&collidingHasher{Hasher: hasher.New()})},
+		{WithKeyType(reflect.TypeOf(0)), WithUnifiedNumericKeys()},
+		{WithKeyType(reflect.TypeOf("")), WithFloatKeyPolicy(hasher.FloatKeysBitwise)},
+	} {
+		if _, err := NewWithOptions(opts...); !errors.Is(err, InvalidOption) {
+			t.Errorf("expected InvalidOption, got %v", err)
+		}
+	}
+	if _, err := NewTypedWithOptions[string, int](WithKeyType(reflect.TypeOf(""))); !errors.Is(err, InvalidOption) {
+		t.Errorf("WithKeyType should not be supported by Typed, got %v", err)
+	}
+}
+
+func benchmarkGetStringKeys(b *testing.B, opts ...Option) {
+	m, err := NewWithOptions(append([]Option{WithBlockSize(1 << 12)}, opts...)...)
+	if err != nil {
+		b.Fatal(err)
+	}
+	keys := make([]Key, 1<<10)
+	for i := range keys {
+		keys[i] = fmt.Sprint("a key longer than 8 bytes ", i)
+		m.Set(keys[i], i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Get(keys[i&(len(keys)-1)])
+	}
+}
+
+func BenchmarkGetStringKeys(b *testing.B) {
+	benchmarkGetStringKeys(b)
+}
+
+func BenchmarkGetStringKeysWithKeyType(b *testing.B) {
+	benchmarkGetStringKeys(b, WithKeyType(reflect.TypeOf("")))
+}
+
+func TestTypedKeyMethods(t *testing.T) {
+	type typedKeyMethods struct {
+		key   Key
+		set   func(m Map, value interface{}) error
+		get   func(m Map) (interface{}, error)
+		swap  func(m Map, value interface{}) (interface{}, error)
+		unset func(m Map) error
+	}
+	var cases []typedKeyMethods
+	for _, key := range []string{"short", "a string longer than 8 bytes"} {
+		key := key
+		cases = append(cases, typedKeyMethods{
+			key:   key,
+			set:   func(m Map, value interface{}) error { return m.SetByString(key, value) },
+			get:   func(m Map) (interface{}, error) { return m.GetByString(key) },
+			swap:  func(m Map, value interface{}) (interface{}, error) { return m.SwapByString(key, value) },
+			unset: func(m Map) error { return m.UnsetByString(key) },
+		}, typedKeyMethods{
+			key:   []byte(key),
+			set:   func(m Map, value interface{}) error { return m.SetByBytes([]byte(key), value) },
+			get:   func(m Map) (interface{}, error) { return m.GetByBytes([]byte(key)) },
+			swap:  func(m Map, value interface{}) (interface{}, error) { return m.SwapByBytes([]byte(key), value) },
+			unset: func(m Map) error { return m.UnsetByBytes([]byte(key)) },
+		})
+	}
+	for _, key := range []uint64{1, math.MaxUint64} {
+		key := key
+		cases = append(cases, typedKeyMethods{
+			key:   key,
+			set:   func(m Map, value interface{}) error { return m.SetByUint64(key, value) },
+			get:   func(m Map) (interface{}, error) { return m.GetByUint64(key) },
+			swap:  func(m Map, value interface{}) (interface{}, error) { return m.SwapByUint64(key, value) },
+			unset: func(m Map) error { return m.UnsetByUint64(key) },
+		}, typedKeyMethods{
+			key:   uintptr(key),
+			set:   func(m Map, value interface{}) error { return m.SetByUintptr(uintptr(key), value) },
+			get:   func(m Map) (interface{}, error) { return m.GetByUintptr(uintptr(key)) },
+			swap:  func(m Map, value interface{}) (interface{}, error) { return m.SwapByUintptr(uintptr(key), value) },
+			unset: func(m Map) error { return m.UnsetByUintptr(uintptr(key)) },
+		})
+	}
+
+	for _, opts := range [][]Option{nil, {WithRandomSeed()}} {
+		for _, c := range cases {
+			m, err := NewWithOptions(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.get(m); err != NotFound {
+				t.Errorf("%T(%v): get from an empty map should return NotFound, got %v", c.key, c.key, err)
+			}
+			if err := c.set(m, 1); err != nil {
+				t.Errorf("%T(%v): set: %v", c.key, c.key, err)
+			}
+			// the keys are the same as of the generic methods
+			if value, err := m.Get(c.key); err != nil || value != 1 {
+				t.Errorf("%T(%v): m.Get() == %v, %v", c.key, c.key, value, err)
+			}
+			m.Set(c.key, 2)
+			if value, err := c.get(m); err != nil || value != 2 {
+				t.Errorf("%T(%v): get == %v, %v", c.key, c.key, value, err)
+			}
+			if oldValue, err := c.swap(m, 3); err != nil || oldValue != 2 {
+				t.Errorf("%T(%v): swap == %v, %v", c.key, c.key, oldValue, err)
+			}
+			if m.Len() != 1 {
+				t.Errorf("%T(%v): m.Len() != 1: %v", c.key, c.key, m.Len())
+			}
+			if err := c.unset(m); err != nil {
+				t.Errorf("%T(%v): unset: %v", c.key, c.key, err)
+			}
+			if err := c.unset(m); err != NotFound {
+				t.Errorf("%T(%v): the second unset should return NotFound, got %v", c.key, c.key, err)
+			}
+			if _, err := m.Get(c.key); err != NotFound {
+				t.Errorf("%T(%v): m.Get() after unset should return NotFound, got %v", c.key, c.key, err)
+			}
+			if err := m.CheckConsistency(); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+
+	m, err := NewWithOptions(WithKeyType(reflect.TypeOf(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		if err := c.set(m, 1); !errors.Is(err, WrongKeyType) {
+			t.Errorf("%T(%v): set to a map of int keys should return WrongKeyType, got %v", c.key, c.key, err)
+		}
+		if _, err := c.get(m); !errors.Is(err, WrongKeyType) {
+			t.Errorf("%T(%v): get from a map of int keys should return WrongKeyType, got %v", c.key, c.key, err)
+		}
+		if _, err := c.swap(m, 1); !errors.Is(err, WrongKeyType) {
+			t.Errorf("%T(%v): swap in a map of int keys should return WrongKeyType, got %v", c.key, c.key, err)
+		}
+		if err := c.unset(m); !errors.Is(err, WrongKeyType) {
+			t.Errorf("%T(%v): unset from a map of int keys should return WrongKeyType, got %v", c.key, c.key, err)
+		}
+	}
+
+	m, err = NewWithOptions(WithUnifiedNumericKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetByUint64(5, "five")
+	if value, err := m.Get(5); err != nil || value != "five" {
+		t.Errorf("m.Get(5) == %v, %v", value, err)
+	}
+	if err := m.UnsetByUintptr(5); err != nil {
+		t.Errorf("m.UnsetByUintptr(5): %v", err)
+	}
+}
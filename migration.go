@@ -0,0 +1,191 @@
+package atomicmap
+
+import (
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// The storage is resized incrementally: a resize (see rehash) only creates
+// the new storage and links it as "next" of the current one. After that:
+//   - new keys are put only to the newest storage;
+//   - each writer moves a chunk of slots of the old storage to the new one
+//     (see helpMigration), and before modifying a key the writer moves the
+//     key itself (see migrateKey);
+//   - readers look for a key in the old storage and then in the next one
+//     (a moved slot is skipped as a removed one);
+//   - when all the slots are moved, the new storage becomes the current one.
+//
+// A moved slot keeps its key and value, so iterating over an old storage
+// still sees the keys which were moved meanwhile.
+
+// migrationChunkSize is how many slots of the old storage a writer moves
+// to the new one per write while the migration is in progress.
+const migrationChunkSize = 64
+
+// lastStorage returns the newest storage (the one where new keys are put
+// to).
+func (m *hashTable[E]) lastStorage() *storage[E] {
+	stor := m.loadStorage()
+	for next := stor.loadNext(); next != nil; next = stor.loadNext() {
+		stor = next
+	}
+	return stor
+}
+
+// helpMigration moves a chunk of slots to the next storage if a migration
+// is in progress. It should be called by writers (between
+// enterWriteSection and leaveWriteSection).
+func (m *hashTable[E]) helpMigration() {
+	stor := m.loadStorage()
+	if stor.loadNext() == nil {
+		return
+	}
+	m.migrateChunk(stor)
+}
+
+// migrateChunk moves the next not handled chunk of slots of "stor" to the
+// next storage. It returns false if all the chunks are already handled (or
+// are being handled by other writers).
+func (m *hashTable[E]) migrateChunk(stor *storage[E]) bool {
+	size := stor.size()
+	startIdxValue := atomic.AddUint64(&stor.migrationCursor, migrationChunkSize) - migrationChunkSize
+	if startIdxValue >= size {
+		return false
+	}
+	endIdxValue := startIdxValue + migrationChunkSize
+	if endIdxValue > size {
+		endIdxValue = size
+	}
+
+	next := stor.loadNext()
+	for idxValue := startIdxValue; idxValue < endIdxValue; idxValue++ {
+		migrateSlot(&stor.items[idxValue], next)
+	}
+
+	if atomic.AddUint64(&stor.migratedCount, endIdxValue-startIdxValue) == size {
+		// All the slots are moved, so the next storage becomes the current one
+		atomic.CompareAndSwapPointer((*unsafe.Pointer)((unsafe.Pointer)(&m.storage)), (unsafe.Pointer)(stor), (unsafe.Pointer)(next))
+	}
+	return true
+}
+
+// migrateKey moves the key to the newest storage if it's still in an old
+// one, so the key could be modified in the newest storage. It should be
+// called by writers (between enterWriteSection and leaveWriteSection).
+func (m *hashTable[E]) migrateKey(fastKey uint64, fastKeyType uint8, hashValue uint64, isRightSlotFn func(*storageSlot[E]) bool) {
+	stor := m.loadStorage()
+	for next := stor.loadNext(); next != nil; stor, next = next, next.loadNext() {
+		slot := stor.findUnmovedSlot(fastKey, fastKeyType, hashValue, isRightSlotFn)
+		if slot != nil {
+			migrateSlot(slot, next)
+		}
+	}
+}
+
+// finishMigration moves all the remaining slots to the newest storage and
+// waits until the migration is finished by other writers (if any).
+func (m *hashTable[E]) finishMigration() {
+	if m.loadStorage().loadNext() == nil {
+		return
+	}
+	if m.threadSafety {
+		m.enterWriteSection(1)
+		defer m.leaveWriteSection(1)
+	}
+	for stor := m.loadStorage(); stor.loadNext() != nil; stor = m.loadStorage() {
+		if !m.migrateChunk(stor) {
+			time.Sleep(lockSleepInterval)
+		}
+	}
+}
+
+// loadSettledStorage finishes the migration (if any) and returns the
+// storage which contains all the keys.
+func (m *hashTable[E]) loadSettledStorage() *storage[E] {
+	for {
+		m.finishMigration()
+		stor := m.loadStorage()
+		if stor.loadNext() == nil {
+			return stor
+		}
+	}
+}
+
+// migrateSlot moves the slot to the storage "next". If the slot is being
+// moved by somebody else then it waits until the slot is moved.
+func migrateSlot[E any](slot *storageSlot[E], next *storage[E]) {
+	for {
+		switch slot.IsSet() {
+		case isSet_notSet, isSet_removed, isSet_moved:
+			return
+		case isSet_set:
+			if !slot.isSet.CompareAndSwap(isSet_set, isSet_updating) {
+				continue
+			}
+			// Waiting for the readers which may modify a counter in place
+			slot.waitForReadersOut()
+			next.insertMigrated(slot)
+			slot.isSet.Store(isSet_moved)
+			return
+		default:
+			time.Sleep(lockSleepInterval)
+		}
+	}
+}
+
+// findUnmovedSlot finds the slot of the key in a storage which is being
+// migrated. The keys of such storage are not changed anymore, so they are
+// read without holding the slots. It returns nil if there's no such key
+// (or if it's already moved).
+func (stor *storage[E]) findUnmovedSlot(fastKey uint64, fastKeyType uint8, hashValue uint64, isRightSlotFn func(*storageSlot[E]) bool) *storageSlot[E] {
+	idxValue := stor.getIdx(hashValue)
+	for {
+		slot := &stor.items[idxValue]
+		idxValue++
+		if idxValue >= stor.size() {
+			idxValue = 0
+		}
+		switch slot.IsSet() {
+		case isSet_notSet:
+			return nil
+		case isSet_removed, isSet_moved:
+			continue
+		}
+		if slot.hashValue != hashValue {
+			continue
+		}
+		if slot.fastKeyType != 0 || fastKeyType != 0 {
+			if slot.fastKey == fastKey && slot.fastKeyType == fastKeyType {
+				return slot
+			}
+			continue
+		}
+		if isRightSlotFn(slot) {
+			return slot
+		}
+	}
+}
+
+// insertMigrated puts a copy of a slot of the previous storage to this one.
+// The key of the slot should not be present in this storage.
+func (stor *storage[E]) insertMigrated(oldSlot *storageSlot[E]) {
+	idxValue := stor.getIdx(oldSlot.hashValue)
+	slid := uint64(0)
+	for {
+		slot := &stor.items[idxValue]
+		if slot.IsSet() == isSet_notSet && slot.isSet.CompareAndSwap(isSet_notSet, isSet_setting) {
+			slot.hashValue = oldSlot.hashValue
+			slot.fastKey, slot.fastKeyType = oldSlot.fastKey, oldSlot.fastKeyType
+			slot.entry = oldSlot.entry
+			slot.slid = slid
+			slot.isSet.Store(isSet_set)
+			return
+		}
+		slid++
+		idxValue++
+		if idxValue >= stor.size() {
+			idxValue = 0
+		}
+	}
+}
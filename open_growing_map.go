@@ -3,29 +3,21 @@
 package atomicmap
 
 import (
+	"bytes"
 	"fmt"
-	"log"
-	"math"
 	"sync/atomic"
 	"time"
-	"unsafe"
 
 	"github.com/xaionaro-go/atomicmap/hasher"
-	"github.com/xaionaro-go/spinlock"
+	I "github.com/xaionaro-go/atomicmap/interfaces"
 )
 
 const (
-	growAtFullness    = 0.85
 	maximalSize       = 1 << 32
 	lockSleepInterval = 300 * time.Nanosecond
 	defaultBlockSize  = 65536
 )
 
-var (
-	threadSafe    = true
-	forbidGrowing = false
-)
-
 type Map = *openAddressGrowingMap
 
 func powerOfTwoGT(v uint64) uint64 {
@@ -39,7 +31,7 @@ func powerOfTwoGT(v uint64) uint64 {
 }
 
 func isPowerOfTwo(v uint64) bool {
-	return (v-1)^v == v
+	return v != 0 && v&(v-1) == 0
 }
 
 func powerOfTwoGE(v uint64) uint64 {
@@ -49,17 +41,17 @@ func powerOfTwoGE(v uint64) uint64 {
 	return powerOfTwoGT(v)
 }
 
-func fixBlockSize(blockSize uint64) uint64 {
+func fixBlockSize(blockSize uint64, logger Logger) uint64 {
 	// This functions fixes blockSize value to be a power of 2
 
 	if blockSize <= 0 {
-		log.Printf("Invalid block size: %v. Setting to %d\n", blockSize, defaultBlockSize)
+		logger.Printf("Invalid block size: %v. Setting to %d\n", blockSize, defaultBlockSize)
 		blockSize = defaultBlockSize
 	}
 
 	if (blockSize-1)^blockSize < blockSize {
 		blockSize = powerOfTwoGT(blockSize)
-		log.Printf("blockSize should be a power of 2 (1, 2, 4, 8, 16, ...). Setting to %v", blockSize)
+		logger.Printf("blockSize should be a power of 2 (1, 2, 4, 8, 16, ...). Setting to %v", blockSize)
 	}
 
 	return blockSize
@@ -71,298 +63,200 @@ func New() Map {
 
 // blockSize should be a power of 2 and should be greater than the maximal amount of elements you're planning to store. Keep in mind: the higher blockSize you'll set the longer initialization will be (and more memory will be consumed).
 func NewWithArgs(blockSize uint64) Map {
-	if blockSize <= 0 {
-		blockSize = defaultBlockSize
+	var opts []Option
+	if blockSize > 0 {
+		opts = append(opts, WithBlockSize(blockSize))
 	}
-	blockSize = fixBlockSize(blockSize)
-	result := &openAddressGrowingMap{initialSize: blockSize, threadSafety: threadSafe}
-	if err := result.growTo(blockSize); err != nil {
+	result, err := NewWithOptions(opts...)
+	if err != nil {
 		panic(err)
 	}
-	result.SetForbidGrowing(forbidGrowing)
 	return result
 }
 
-func newWithArgsIface(blockSize uint64) iMap {
-	return NewWithArgs(blockSize)
-}
-
-func (m *openAddressGrowingMap) SetThreadSafety(threadSafety bool) {
-	m.threadSafety = threadSafety
-}
-
-func (m *openAddressGrowingMap) IsForbiddenToGrow() bool {
-	return atomic.LoadInt32(&m.forbidGrowing) != 0
-}
-
-func (m *openAddressGrowingMap) SetForbidGrowing(forbidGrowing bool) {
-	if forbidGrowing {
-		atomic.StoreInt32(&m.forbidGrowing, 1)
-	} else {
-		if atomic.LoadInt32(&m.forbidGrowing) != 0 {
-			panic(`Not supported, yet: you cannot reenable growing`)
-		}
-		atomic.StoreInt32(&m.forbidGrowing, 0)
+// NewWithOptions creates a map configured by the options (see Option). The
+// map is configured independently of other maps.
+func NewWithOptions(opts ...Option) (Map, error) {
+	cfg, err := newOptions(opts)
+	if err != nil {
+		return nil, err
 	}
-}
-
-/*func (m *openAddressGrowingMap) increaseConcurrency() {
-	if !m.threadSafety {
-		return
-	}
-	if m.IsForbiddenToGrow() {
-		return
+	result := &openAddressGrowingMap{hasher: cfg.hasher}
+	result.strictHasher, _ = cfg.hasher.(*hasher.StrictHasher)
+	if err := result.init(&cfg); err != nil {
+		return nil, err
 	}
+	return result, nil
 }
-func (m *openAddressGrowingMap) decreaseConcurrency() {
-	if !m.threadSafety {
-		return
-	}
-	if m.IsForbiddenToGrow() {
-		return
-	}
-}*/
 
-func (m *openAddressGrowingMap) lock() {
-	if !m.threadSafety {
-		return
-	}
-	m.locker.Lock()
+func newWithArgsIface(blockSize uint64) iMap {
+	return NewWithArgs(blockSize)
 }
-func (m *openAddressGrowingMap) unlock() {
-	if !m.threadSafety {
-		return
-	}
-	m.locker.Unlock()
+
+func newThreadUnsafeWithArgsIface(blockSize uint64) iMap {
+	result := NewWithArgs(blockSize)
+	result.SetThreadSafety(false)
+	return result
 }
 
 type openAddressGrowingMap struct {
-	initialSize uint64
-	busySlots   int64
+	hasher I.Hasher // nil means the default hasher (package "hasher")
 
-	*storage
+	// strictHasher is the same as hasher if the key type is pinned (see
+	// WithKeyType), otherwise nil. It's called directly, without a dynamic
+	// dispatch.
+	strictHasher *hasher.StrictHasher
 
-	writeConcurrency int32
-	threadSafety     bool
-	forbidGrowing    int32
-	isGrowing        int32
-	locker           spinlock.Locker
+	hashTable[mapEntry]
 }
 
-func (m *openAddressGrowingMap) waitUntilNoWrite() {
-	for atomic.LoadInt32(&m.writeConcurrency) != 0 {
-		time.Sleep(lockSleepInterval)
-	}
-}
-
-func (m *openAddressGrowingMap) isEnoughFreeSpace() bool {
-	return float64(m.BusySlots()+uint64(atomic.LoadInt32(&m.writeConcurrency)))/float64(len(m.items)) < growAtFullness
-}
-func (m *openAddressGrowingMap) concedeToGrowing() {
-	for atomic.LoadInt32(&m.isGrowing) != 0 {
-		time.Sleep(lockSleepInterval)
-	}
-}
 func (m *openAddressGrowingMap) SetBytesByBytes(key []byte, value []byte) error {
-	return m.set(func() (uint64, uint8, bool) {
-		return hasher.PreHashBytes(key)
-	}, func(slot *mapSlot) bool {
-		return hasher.IsEqualKey(slot.key, key)
-	}, func(slot *mapSlot) {
-		slot.key = key
+	if err := m.checkKeyType(bytesKeyType); err != nil {
+		return err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashBytes(key)
+	return m.set(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return m.isEqualKey(slot.entry.key, key)
 	}, func(slot *mapSlot) {
-		slot.bytesValue = value
+		slot.entry.key = key
+	}, func(slot *mapSlot) bool {
+		slot.entry.setValue(nil)
+		slot.entry.bytesValue = value
+		return true
 	})
 }
 func (m *openAddressGrowingMap) SetByUintptrUsingFunc(key uintptr, setValueFunc func(v *interface{})) error {
-	return m.set(func() (uint64, uint8, bool) {
-		return hasher.PreHashUintptr(key)
-	}, func(slot *mapSlot) bool {
-		return hasher.IsEqualKey(slot.key, key)
-	}, func(slot *mapSlot) {
-		slot.key = key
+	if err := m.checkKeyType(uintptrKeyType); err != nil {
+		return err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashUintptr(key)
+	return m.set(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return m.isEqualKey(slot.entry.key, key)
 	}, func(slot *mapSlot) {
-		setValueFunc(&slot.value)
+		slot.entry.key = key
+	}, func(slot *mapSlot) bool {
+		slot.entry.setValue(slot.entry.getValue())
+		setValueFunc(&slot.entry.value)
+		return true
 	})
 }
 func (m *openAddressGrowingMap) Set(key Key, value interface{}) error {
-	return m.set(func() (uint64, uint8, bool) {
-		return hasher.PreHash(key)
-	}, func(slot *mapSlot) bool {
-		return hasher.IsEqualKey(slot.key, key)
-	}, func(slot *mapSlot) {
-		slot.key = key
+	preHashValue, typeID, preHashValueIsFull, err := m.preHashKey(key)
+	if err != nil {
+		return err
+	}
+	return m.set(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return m.isEqualKey(slot.entry.key, key)
 	}, func(slot *mapSlot) {
-		slot.value = value
+		slot.entry.key = key
+	}, func(slot *mapSlot) bool {
+		slot.entry.setValue(value)
+		return true
 	})
 }
 func (m *openAddressGrowingMap) Swap(key Key, value interface{}) (oldValue interface{}, err error) {
-	err = m.set(func() (uint64, uint8, bool) {
-		return hasher.PreHash(key)
-	}, func(slot *mapSlot) bool {
-		return hasher.IsEqualKey(slot.key, key)
-	}, func(slot *mapSlot) {
-		slot.key = key
+	preHashValue, typeID, preHashValueIsFull, err := m.preHashKey(key)
+	if err != nil {
+		return nil, err
+	}
+	err = m.set(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return m.isEqualKey(slot.entry.key, key)
 	}, func(slot *mapSlot) {
-		oldValue = slot.value
-		slot.value = value
+		slot.entry.key = key
+	}, func(slot *mapSlot) bool {
+		oldValue = slot.entry.getValue()
+		slot.entry.setValue(value)
+		return true
 	})
 	return
 }
 
-func (m *openAddressGrowingMap) set(getPreHash func() (uint64, uint8, bool), compareKey func(*mapSlot) bool, setKey func(*mapSlot), setValue func(*mapSlot)) error {
-	/*if m.currentSize == len(m.storage) {
-		return NoSpaceLeft
-	}*/
-	if m.threadSafety {
-		m.concedeToGrowing()
-		if !m.isEnoughFreeSpace() {
-			if err := m.growTo(m.size() << 1); err != nil {
-				return err
-			}
-		}
-		atomic.AddInt32(&m.writeConcurrency, 1)
-		//m.increaseConcurrency()
-	}
-
-	preHashValue, typeID, preHashValueIsFull := getPreHash()
-	hashValue := hasher.CompleteHash(preHashValue, typeID)
-	idxValue := m.getIdx(hashValue)
-	if !preHashValueIsFull {
-		typeID = 0
-	}
-
-	var slot *mapSlot
-	slid := uint64(0)
-	for { // Going forward through the storage while a collision (to find a free slots)
-		slot = &m.items[idxValue].mapSlot
-		if slot.isSet.CompareAndSwap(isSet_notSet, isSet_setting) {
-			break
-		} else {
-			if slot.isSet.CompareAndSwap(isSet_removed, isSet_setting) {
-				break
-			}
-		}
-		if m.threadSafety {
-			if !slot.setIsUpdating() {
-				if slot.isSet.CompareAndSwap(isSet_removed, isSet_setting) {
-					break
-				} else {
-					continue // try again
-				}
-			}
+// GetOrSet returns the existing value for the key if present. Otherwise, it
+// sets the value and returns it. The "loaded" result is true if the value
+// was loaded, false if set. The semantics is the same as of
+// sync.Map.LoadOrStore: if several goroutines call GetOrSet for the same
+// absent key concurrently, only one of the values is set and all of them
+// get it as "actual".
+func (m *openAddressGrowingMap) GetOrSet(key Key, value interface{}) (actual interface{}, loaded bool, err error) {
+	preHashValue, typeID, preHashValueIsFull, err := m.preHashKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+	isNewSlot := false
+	err = m.set(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return m.isEqualKey(slot.entry.key, key)
+	}, func(slot *mapSlot) {
+		slot.entry.key = key
+		isNewSlot = true
+	}, func(slot *mapSlot) bool {
+		if isNewSlot {
+			slot.entry.setValue(value)
+			actual = value
+			return true
 		}
-		if slot.hashValue == hashValue {
-			var isEqualKey bool
-			if typeID != 0 || slot.fastKeyType != 0 {
-				isEqualKey = slot.fastKey == preHashValue && slot.fastKeyType == typeID
-			} else {
-				isEqualKey = compareKey(slot)
-			}
+		actual, loaded = slot.entry.getValue(), true
+		return true
+	})
+	return
+}
 
-			if isEqualKey {
-				if m.threadSafety {
-					slot.waitForReadersOut()
-				}
-				setValue(slot)
-				if m.threadSafety {
-					slot.isSet.Store(isSet_set)
-					atomic.AddInt32(&m.writeConcurrency, -1)
-					//m.decreaseConcurrency()
-				}
-				return nil
-			}
-		}
-		slot.isSet.Store(isSet_set)
-		slid++
-		idxValue++
-		if idxValue >= m.size() {
-			idxValue = 0
-		}
-		if slid > m.size() {
-			panic(fmt.Errorf("%v %v %v %v", slid, m.size(), m.BusySlots(), m.isGrowing))
+// UpdateFunc gets the current value of a key (and whether the key exists)
+// and returns the new value and whether the key should be kept in the map.
+type UpdateFunc func(oldValue interface{}, exists bool) (newValue interface{}, keep bool)
+
+// Update atomically replaces the value of the key with the value returned by
+// fn. If fn returns keep == false, the key is removed (or is not added if it
+// didn't exist).
+//
+// fn is called while the slot of the key is locked and no readers use it,
+// so concurrent Update-s of the same key are serialized (for example, it's
+// safe to increment a counter this way). fn should not access the same key
+// of the map. If fn panics, the key is left unchanged.
+func (m *openAddressGrowingMap) Update(key Key, fn UpdateFunc) error {
+	preHashValue, typeID, preHashValueIsFull, err := m.preHashKey(key)
+	if err != nil {
+		return err
+	}
+	isNewSlot := false
+	return m.set(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return m.isEqualKey(slot.entry.key, key)
+	}, func(slot *mapSlot) {
+		slot.entry.key = key
+		isNewSlot = true
+	}, func(slot *mapSlot) bool {
+		var oldValue interface{}
+		if !isNewSlot {
+			oldValue = slot.entry.getValue()
 		}
-	}
-
-	slot.hashValue = hashValue
-	if preHashValueIsFull {
-		slot.fastKey, slot.fastKeyType = preHashValue, typeID
-	}
-	setKey(slot)
-	setValue(slot)
-	slot.slid = slid
-	atomic.AddInt64(&m.busySlots, 1)
-	slot.isSet.Store(isSet_set)
-
-	if m.threadSafety {
-		atomic.AddInt32(&m.writeConcurrency, -1)
-		//m.decreaseConcurrency()
-	}
-	if !m.isEnoughFreeSpace() {
-		if err := m.growTo(m.size() << 1); err != nil {
-			return nil
+		newValue, keep := fn(oldValue, !isNewSlot)
+		if !keep {
+			return false
 		}
-	}
-	return nil
-}
-
-func copySlot(newSlot, oldSlot *mapSlot) { // is sligtly faster than "*newSlot = *oldSlot"
-	newSlot.isSet = oldSlot.isSet
-	newSlot.hashValue = oldSlot.hashValue
-	newSlot.key = oldSlot.key
-	newSlot.fastKey, newSlot.fastKeyType = oldSlot.fastKey, oldSlot.fastKeyType
-	newSlot.value = oldSlot.value
+		slot.entry.setValue(newValue)
+		return true
+	})
 }
 
-func (m *openAddressGrowingMap) growTo(newSize uint64) error {
-	if m.IsForbiddenToGrow() {
-		return ForbiddenToGrow
-	}
-
-	if newSize > maximalSize {
-		return NoSpaceLeft
-	}
-
-	if m.size() >= newSize {
-		return nil
-	}
-
-	if m.threadSafety {
-		if !atomic.CompareAndSwapInt32(&m.isGrowing, 0, 1) {
-			return AlreadyGrowing
-		}
-		defer atomic.StoreInt32(&m.isGrowing, 0)
-
-		m.lock()
-		defer m.unlock()
-		m.waitUntilNoWrite()
-	}
-
-	if m.size() >= newSize {
-		return nil
-	}
-
-	newStorage := newStorage(newSize)
-	newStorage.copyOldItemsAfterGrowing(m.storage)
-	atomic.StorePointer((*unsafe.Pointer)((unsafe.Pointer)(&m.storage)), (unsafe.Pointer)(newStorage))
-	return nil
+// set finds the slot of the pre-hashed key (see hashTable.setHashed). If
+// setValue returns false, the key is removed (or is not added if it's a new
+// one).
+func (m *openAddressGrowingMap) set(preHashValue uint64, typeID uint8, preHashValueIsFull bool, compareKey func(*mapSlot) bool, setKey func(*mapSlot), setValue func(*mapSlot) bool) error {
+	hashValue := m.completeHash(preHashValue, typeID)
+	return m.setHashed(preHashValue, typeID, preHashValueIsFull, hashValue, compareKey, setKey, setValue)
 }
 
 func (m *openAddressGrowingMap) GetByUintptr(key uintptr) (interface{}, error) {
+	if err := m.checkKeyType(uintptrKeyType); err != nil {
+		return nil, err
+	}
 	if m.BusySlots() == 0 {
 		return nil, NotFound
 	}
 	//m.increaseConcurrency()
 
-	preHashValue, typeID, preHashValueIsFull := hasher.PreHashUintptr(key)
-	hashValue := hasher.CompleteHash(preHashValue, typeID)
-	var fastKey uint64
-	var fastKeyType uint8
-	if preHashValueIsFull {
-		fastKey, fastKeyType = preHashValue, typeID
-	}
+	fastKey, fastKeyType, hashValue := m.hashUintptr(key)
 	return m.getByHashValue(fastKey, fastKeyType, hashValue, func(slot *mapSlot) bool {
-		slotKey, ok := slot.key.(uintptr)
+		slotKey, ok := slot.entry.key.(uintptr)
 		if !ok {
 			return false
 		}
@@ -374,20 +268,17 @@ func (m *openAddressGrowingMap) GetByUintptr(key uintptr) (interface{}, error) {
 }
 
 func (m *openAddressGrowingMap) GetByUint64(key uint64) (interface{}, error) {
+	if err := m.checkKeyType(uint64KeyType); err != nil {
+		return nil, err
+	}
 	if m.BusySlots() == 0 {
 		return nil, NotFound
 	}
 	//m.increaseConcurrency()
 
-	preHashValue, typeID, preHashValueIsFull := hasher.PreHashUint64(key)
-	hashValue := hasher.CompleteHash(preHashValue, typeID)
-	var fastKey uint64
-	var fastKeyType uint8
-	if preHashValueIsFull {
-		fastKey, fastKeyType = preHashValue, typeID
-	}
+	fastKey, fastKeyType, hashValue := m.hashUint64(key)
 	return m.getByHashValue(fastKey, fastKeyType, hashValue, func(slot *mapSlot) bool {
-		slotKey, ok := slot.key.(uint64)
+		slotKey, ok := slot.entry.key.(uint64)
 		if !ok {
 			return false
 		}
@@ -399,20 +290,17 @@ func (m *openAddressGrowingMap) GetByUint64(key uint64) (interface{}, error) {
 }
 
 func (m *openAddressGrowingMap) GetByBytes(key []byte) (interface{}, error) {
+	if err := m.checkKeyType(bytesKeyType); err != nil {
+		return nil, err
+	}
 	if m.BusySlots() == 0 {
 		return nil, NotFound
 	}
 	//m.increaseConcurrency()
 
-	preHashValue, typeID, preHashValueIsFull := hasher.PreHashBytes(key)
-	hashValue := hasher.CompleteHash(preHashValue, typeID)
-	var fastKey uint64
-	var fastKeyType uint8
-	if preHashValueIsFull {
-		fastKey, fastKeyType = preHashValue, typeID
-	}
+	fastKey, fastKeyType, hashValue := m.hashBytes(key)
 	return m.getByHashValue(fastKey, fastKeyType, hashValue, func(slot *mapSlot) bool {
-		slotKey, ok := slot.key.([]byte)
+		slotKey, ok := slot.entry.key.([]byte)
 		if !ok {
 			return false
 		}
@@ -429,232 +317,172 @@ func (m *openAddressGrowingMap) GetByBytes(key []byte) (interface{}, error) {
 	})
 }
 
+// Get returns the value of the key or NotFound if there's no such key.
+//
+// Get doesn't lock the map. The storage pointer is loaded atomically once
+// per lookup, and if a resize is in progress then the lookup follows the old
+// storage to the new one (see migration.go). So a Get racing with a resize
+// returns either the value before or the value after a concurrent Set() of
+// the same key, and never a spurious NotFound. A storage left behind by a
+// resize is freed by the garbage collector once the last reader is done
+// with it.
 func (m *openAddressGrowingMap) Get(key Key) (interface{}, error) {
+	fastKey, fastKeyType, hashValue, err := m.hashKey(key)
+	if err != nil {
+		return nil, err
+	}
 	if m.BusySlots() == 0 {
 		return nil, NotFound
 	}
 	//m.increaseConcurrency()
 
-	preHashValue, typeID, preHashValueIsFull := hasher.PreHash(key)
-	hashValue := hasher.CompleteHash(preHashValue, typeID)
-	var fastKey uint64
-	var fastKeyType uint8
-	if preHashValueIsFull {
-		fastKey, fastKeyType = preHashValue, typeID
-	}
 	return m.getByHashValue(fastKey, fastKeyType, hashValue, func(slot *mapSlot) bool {
-		return hasher.IsEqualKey(slot.key, key)
+		return m.isEqualKey(slot.entry.key, key)
 	})
 }
 
 func (m *openAddressGrowingMap) getByHashValue(fastKey uint64, fastKeyType uint8, hashValue uint64, isRightSlotFn func(*mapSlot) bool) (interface{}, error) {
-	idxValue := m.getIdx(hashValue)
-
-	for {
-		slot := &m.items[idxValue].mapSlot
-		idxValue++
-		if idxValue >= m.size() {
-			idxValue = 0
-		}
-		var isSetStatus isSet
-		if m.threadSafety {
-			isSetStatus = slot.increaseReaders()
-		} else {
-			isSetStatus = slot.IsSet()
-		}
-		if isSetStatus == isSet_notSet {
-			break
-		} else if isSetStatus == isSet_removed {
-			continue
-		} else if isSetStatus != isSet_set {
-			panic("shouldn't happened")
-		}
-
-		if slot.hashValue != hashValue {
-			if m.threadSafety {
-				slot.decreaseReaders()
-			}
-			continue
-		}
-		var isRightSlot bool
-		if slot.fastKeyType != 0 || fastKeyType != 0 {
-			isRightSlot = slot.fastKey == fastKey && slot.fastKeyType == fastKeyType
-		} else {
-			isRightSlot = isRightSlotFn(slot)
-		}
-		if !isRightSlot {
-			if m.threadSafety {
-				slot.decreaseReaders()
-			}
-			continue
-		}
-
-		var value interface{}
-		if slot.bytesValue != nil {
-			value = slot.bytesValue
-		} else {
-			value = slot.value
-		}
-		if m.threadSafety {
-			slot.decreaseReaders()
-		}
+	slot := m.getSlotByHashValue(fastKey, fastKeyType, hashValue, isRightSlotFn)
+	if slot == nil {
 		//m.decreaseConcurrency()
-		return value, nil
+		return nil, NotFound
 	}
 
+	value := slot.entry.getValue()
+	if m.threadSafety {
+		slot.decreaseReaders()
+	}
 	//m.decreaseConcurrency()
-	return nil, NotFound
+	return value, nil
 }
 
-// loopy slid handler on free'ing a slot
-func (m *openAddressGrowingMap) setEmptySlot(idxValue uint64, slot *mapSlot) {
-	m.lock()
-
-	// searching for a replacement to the slot (if somebody slid forward)
-	slid := uint64(0)
-	realRemoveIdxValue := idxValue
-	freeIdxValue := idxValue
-	freeSlot := slot
-	for {
-		slid++
-		realRemoveIdxValue++
-		if realRemoveIdxValue >= m.size() {
-			realRemoveIdxValue = 0
-		}
-		realRemoveSlot := &m.items[realRemoveIdxValue].mapSlot
-		if realRemoveSlot.isSet == isSet_notSet {
-			break
-		}
-		if realRemoveSlot.slid < slid {
-			continue
-		}
-
-		// searching for the last slot to move
-		previousRealRemoveIdxValue := realRemoveIdxValue
-		previousRealRemoveSlot := realRemoveSlot
-		for {
-			slid++
-			realRemoveIdxValue++
-			if realRemoveIdxValue >= m.size() {
-				realRemoveIdxValue = 0
-			}
-			realRemoveSlot := &m.items[realRemoveIdxValue].mapSlot
-			if realRemoveSlot.isSet == isSet_notSet {
-				break
-			}
-			if realRemoveSlot.slid < slid {
-				continue
-			}
-			previousRealRemoveIdxValue = realRemoveIdxValue
-			previousRealRemoveSlot = realRemoveSlot
-		}
-		realRemoveIdxValue = previousRealRemoveIdxValue
-		realRemoveSlot = previousRealRemoveSlot
-
-		*freeSlot = *realRemoveSlot
-		freeSlot.slid -= realRemoveIdxValue - freeIdxValue
-
-		freeSlot = realRemoveSlot
-		freeIdxValue = realRemoveIdxValue
-		slid = 0
-	}
+type ConditionFunc func(value interface{}) bool
 
-	freeSlot.value = nil
-	freeSlot.isSet = isSet_notSet
-	atomic.AddInt64(&m.busySlots, -1)
-	m.unlock()
+// lockSlotByKey finds the slot of the key pre-hashed by preHashKey and holds
+// it in state "updating" (see hashTable.lockSlotByHashValue).
+func (m *openAddressGrowingMap) lockSlotByKey(key Key, preHashValue uint64, typeID uint8, preHashValueIsFull bool) *mapSlot {
+	return m.lockSlotByPreHash(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return m.isEqualKey(slot.entry.key, key)
+	})
 }
 
-type ConditionFunc func(value interface{}) bool
-
-func (m *openAddressGrowingMap) unset(key Key, conditionFunc ConditionFunc) (*mapSlot, uint64) {
-	preHashValue, typeID, preHashValueIsFull := hasher.PreHash(key)
-	hashValue := hasher.CompleteHash(preHashValue, typeID)
-	idxValue := m.getIdx(hashValue)
+// lockSlotByPreHash is the same as lockSlotByKey, but the key is compared
+// by compareKey.
+func (m *openAddressGrowingMap) lockSlotByPreHash(preHashValue uint64, typeID uint8, preHashValueIsFull bool, compareKey func(*mapSlot) bool) *mapSlot {
+	hashValue := m.completeHash(preHashValue, typeID)
 	if !preHashValueIsFull {
 		typeID = 0
 	}
+	slot, _ := m.lockSlotByHashValue(preHashValue, typeID, hashValue, compareKey)
+	return slot
+}
+func (m *openAddressGrowingMap) Unset(key Key) error {
+	return m.UnsetIf(key, nil)
+}
 
-	for {
-		slot := &m.items[idxValue].mapSlot
-		curIdxValue := idxValue
-		idxValue++
-		if idxValue >= m.size() {
-			idxValue = 0
-		}
-		switch slot.IsSet() {
-		case isSet_notSet:
-			return nil, 0
-		case isSet_removed:
-			continue
-		}
-		if m.threadSafety {
-			if !slot.setIsUpdating() {
-				continue
-			}
-		}
-		if slot.hashValue != hashValue {
-			slot.isSet.Store(isSet_set)
-			continue
-		}
+// UnsetIf removes the key only if conditionFunc returns true for the value
+// (or if conditionFunc is nil). conditionFunc is called while the slot is
+// locked, so it should not access the same key of the map.
+func (m *openAddressGrowingMap) UnsetIf(key Key, conditionFunc ConditionFunc) error {
+	err := m.unsetIf(key, conditionFunc)
+	if err == nil {
+		m.shrinkIfNeeded()
+	}
+	return err
+}
 
-		var isEqualKey bool
-		if slot.fastKeyType != 0 || typeID != 0 {
-			isEqualKey = slot.fastKey == preHashValue && slot.fastKeyType == typeID
-		} else {
-			isEqualKey = hasher.IsEqualKey(slot.key, key)
-		}
-		if !isEqualKey {
-			slot.isSet.Store(isSet_set)
-			continue
-		}
-		if conditionFunc != nil {
-			var value interface{}
-			if slot.bytesValue != nil {
-				value = slot.bytesValue
-			} else {
-				value = slot.value
-			}
-			if !conditionFunc(value) {
-				slot.isSet.Store(isSet_set)
-				return nil, curIdxValue
-			}
+func (m *openAddressGrowingMap) unsetIf(key Key, conditionFunc ConditionFunc) error {
+	preHashValue, typeID, preHashValueIsFull, err := m.preHashKey(key)
+	if err != nil {
+		return err
+	}
+	var isRightValue func(*mapSlot) bool
+	if conditionFunc != nil {
+		isRightValue = func(slot *mapSlot) bool {
+			return conditionFunc(slot.entry.getValue())
 		}
+	}
+	return m.unset(func() *mapSlot {
+		return m.lockSlotByKey(key, preHashValue, typeID, preHashValueIsFull)
+	}, isRightValue)
+}
 
-		slot.isSet.Store(isSet_set)
-		return slot, curIdxValue
+// EqualFunc reports whether the current value of a key is equal to the
+// expected one.
+type EqualFunc func(currentValue, expectedValue interface{}) bool
+
+// isEqualValue compares values with "==", except []byte values (which are
+// compared by content).
+func isEqualValue(currentValue, expectedValue interface{}) bool {
+	if currentBytes, ok := currentValue.([]byte); ok {
+		expectedBytes, ok := expectedValue.([]byte)
+		return ok && bytes.Equal(currentBytes, expectedBytes)
 	}
-	return nil, math.MaxUint64
+	return currentValue == expectedValue
 }
-func (m *openAddressGrowingMap) Unset(key Key) error {
-	return m.UnsetIf(key, nil)
+
+// CompareAndSwap sets newValue for the key if the current value is equal to
+// oldValue. The values are compared with "==" (which panics if the values are
+// not comparable, use CompareAndSwapFunc for such values). It returns
+// NotFound if there's no such key.
+func (m *openAddressGrowingMap) CompareAndSwap(key Key, oldValue, newValue interface{}) (swapped bool, err error) {
+	return m.CompareAndSwapFunc(key, oldValue, newValue, isEqualValue)
 }
-func (m *openAddressGrowingMap) UnsetIf(key Key, conditionFunc ConditionFunc) error {
+
+// CompareAndSwapFunc is the same as CompareAndSwap, but the values are
+// compared with isEqual. isEqual is called while the slot is locked, so it
+// should not access the same key of the map. If isEqual panics, the key is
+// left unchanged.
+func (m *openAddressGrowingMap) CompareAndSwapFunc(key Key, oldValue, newValue interface{}, isEqual EqualFunc) (swapped bool, err error) {
+	preHashValue, typeID, preHashValueIsFull, err := m.preHashKey(key)
+	if err != nil {
+		return false, err
+	}
 	if m.BusySlots() == 0 {
-		return NotFound
+		return false, NotFound
 	}
-	//m.increaseConcurrency()
-	atomic.AddInt32(&m.writeConcurrency, 1)
-	//slot, idx := m.unset(key)
-	slot, idx := m.unset(key, conditionFunc)
-	atomic.AddInt32(&m.writeConcurrency, -1)
-	//m.decreaseConcurrency()
+	if m.threadSafety {
+		m.enterWriteSection(1)
+		defer m.leaveWriteSection(1)
+	}
+	slot := m.lockSlotByKey(key, preHashValue, typeID, preHashValueIsFull)
 	if slot == nil {
-		if idx == math.MaxUint64 {
-			return NotFound
-		} else {
-			return ConditionFailed
-		}
+		return false, NotFound
 	}
-	//if m.IsForbiddenToGrow() {
-	slot.value = nil
-	slot.bytesValue = nil
-	slot.isSet.Store(isSet_removed)
-	atomic.AddInt64(&m.busySlots, -1)
-	//} else {
-	//	m.setEmptySlot(idx, slot)
-	//}
-	return nil
+	if !m.callLocked(slot, false, func(slot *mapSlot) bool {
+		return isEqual(slot.entry.getValue(), oldValue)
+	}) {
+		slot.isSet.Store(isSet_set)
+		return false, nil
+	}
+	if m.threadSafety {
+		slot.waitForReadersOut()
+	}
+	slot.entry.setValue(newValue)
+	slot.isSet.Store(isSet_set)
+	return true, nil
+}
+
+// CompareAndDelete removes the key if its value is equal to oldValue. The
+// values are compared with "==" (see CompareAndSwap). It returns NotFound if
+// there's no such key.
+func (m *openAddressGrowingMap) CompareAndDelete(key Key, oldValue interface{}) (deleted bool, err error) {
+	return m.CompareAndDeleteFunc(key, oldValue, isEqualValue)
+}
+
+// CompareAndDeleteFunc is the same as CompareAndDelete, but the values are
+// compared with isEqual (see CompareAndSwapFunc).
+func (m *openAddressGrowingMap) CompareAndDeleteFunc(key Key, oldValue interface{}, isEqual EqualFunc) (deleted bool, err error) {
+	err = m.UnsetIf(key, func(value interface{}) bool {
+		return isEqual(value, oldValue)
+	})
+	switch err {
+	case nil:
+		return true, nil
+	case ConditionFailed:
+		return false, nil
+	}
+	return false, err
 }
 
 func (m *openAddressGrowingMap) Len() int {
@@ -670,53 +498,247 @@ func (m *openAddressGrowingMap) BusySlots() uint64 {
 	return uint64(atomic.LoadInt64(&m.busySlots))
 }
 
-/*func (m *openAddressGrowingMap) Reset() {
-	m.lock()
-	*m = openAddressGrowingMap{initialSize: m.initialSize, concurrency: -1, threadSafety: threadSafe}
-	m.growTo(m.initialSize)
-}*/
-
+// Hash returns the hash value of the key, calculated by the hasher of the
+// map (see WithHasher).
 func (m *openAddressGrowingMap) Hash(key Key) uint64 {
+	if m.hasher != nil {
+		return m.hasher.Hash(key)
+	}
 	return hasher.Hash(key)
 }
 
+// The methods below call the hasher of the map. If the default hasher is
+// used (m.hasher == nil) then the functions of package "hasher" are called
+// directly, without a dynamic dispatch.
+
+// hashKey, hashString, hashBytes, hashUint64 and hashUintptr hash the key for a lookup:
+// they return the fast key (see storageSlot.fastKey) and the hash value.
+// m.hasher is checked once per lookup, and with the default hasher both
+// the pre-hash and the hash are calculated by direct calls to the functions
+// of package "hasher". hashKey also rejects a key of a wrong type (see
+// preHashKey).
+
+func (m *openAddressGrowingMap) hashKey(key Key) (uint64, uint8, uint64, error) {
+	if m.hasher == nil {
+		fastKey, fastKeyType, hashValue := completeLookupHash(hasher.PreHash(key))
+		return fastKey, fastKeyType, hashValue, nil
+	}
+	preHashValue, typeID, preHashValueIsFull, err := m.preHashKey(key)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	fastKey, fastKeyType, hashValue := m.completeLookupHash(preHashValue, typeID, preHashValueIsFull)
+	return fastKey, fastKeyType, hashValue, nil
+}
+
+func (m *openAddressGrowingMap) hashString(key string) (uint64, uint8, uint64) {
+	if m.hasher != nil {
+		return m.completeLookupHash(m.hasher.PreHashString(key))
+	}
+	return completeLookupHash(hasher.PreHashString(key))
+}
+
+func (m *openAddressGrowingMap) hashBytes(key []byte) (uint64, uint8, uint64) {
+	if m.hasher != nil {
+		return m.completeLookupHash(m.hasher.PreHashBytes(key))
+	}
+	return completeLookupHash(hasher.PreHashBytes(key))
+}
+
+func (m *openAddressGrowingMap) hashUint64(key uint64) (uint64, uint8, uint64) {
+	if m.hasher != nil {
+		return m.completeLookupHash(m.hasher.PreHashUint64(key))
+	}
+	return completeLookupHash(hasher.PreHashUint64(key))
+}
+
+func (m *openAddressGrowingMap) hashUintptr(key uintptr) (uint64, uint8, uint64) {
+	if m.hasher != nil {
+		return m.completeLookupHash(m.hasher.PreHashUintptr(key))
+	}
+	return completeLookupHash(hasher.PreHashUintptr(key))
+}
+
+// completeLookupHash completes the pre-hash by the default hasher and
+// returns the fast key (zero if the pre-hash is not full) and the hash value.
+func completeLookupHash(preHashValue uint64, typeID uint8, preHashValueIsFull bool) (fastKey uint64, fastKeyType uint8, hashValue uint64) {
+	if preHashValueIsFull {
+		fastKey, fastKeyType = preHashValue, typeID
+	}
+	return fastKey, fastKeyType, hasher.CompleteHash(preHashValue, typeID)
+}
+
+// completeLookupHash is the same as the function completeLookupHash, but
+// for a custom hasher.
+func (m *openAddressGrowingMap) completeLookupHash(preHashValue uint64, typeID uint8, preHashValueIsFull bool) (fastKey uint64, fastKeyType uint8, hashValue uint64) {
+	if preHashValueIsFull {
+		fastKey, fastKeyType = preHashValue, typeID
+	}
+	return fastKey, fastKeyType, m.completeHash(preHashValue, typeID)
+}
+
+func (m *openAddressGrowingMap) preHash(key Key) (uint64, uint8, bool) {
+	if m.strictHasher != nil {
+		return m.strictHasher.PreHash(key)
+	}
+	if m.hasher != nil {
+		return m.hasher.PreHash(key)
+	}
+	return hasher.PreHash(key)
+}
+
+func (m *openAddressGrowingMap) preHashString(key string) (uint64, uint8, bool) {
+	if m.hasher != nil {
+		return m.hasher.PreHashString(key)
+	}
+	return hasher.PreHashString(key)
+}
+
+func (m *openAddressGrowingMap) preHashBytes(key []byte) (uint64, uint8, bool) {
+	if m.hasher != nil {
+		return m.hasher.PreHashBytes(key)
+	}
+	return hasher.PreHashBytes(key)
+}
+
+func (m *openAddressGrowingMap) preHashUint64(key uint64) (uint64, uint8, bool) {
+	if m.hasher != nil {
+		return m.hasher.PreHashUint64(key)
+	}
+	return hasher.PreHashUint64(key)
+}
+
+func (m *openAddressGrowingMap) preHashUintptr(key uintptr) (uint64, uint8, bool) {
+	if m.hasher != nil {
+		return m.hasher.PreHashUintptr(key)
+	}
+	return hasher.PreHashUintptr(key)
+}
+
+func (m *openAddressGrowingMap) completeHash(preHashValue uint64, typeID uint8) uint64 {
+	if m.strictHasher != nil {
+		return m.strictHasher.CompleteHash(preHashValue, typeID)
+	}
+	if m.hasher != nil {
+		return m.hasher.CompleteHash(preHashValue, typeID)
+	}
+	return hasher.CompleteHash(preHashValue, typeID)
+}
+
+func (m *openAddressGrowingMap) isEqualKey(keyA, keyB Key) bool {
+	if m.strictHasher != nil {
+		return m.strictHasher.IsEqualKey(keyA, keyB)
+	}
+	if m.hasher != nil {
+		return m.hasher.IsEqualKey(keyA, keyB)
+	}
+	return hasher.IsEqualKey(keyA, keyB)
+}
+
 func (m *openAddressGrowingMap) CheckConsistency() error {
+	stor := m.loadSettledStorage()
 	m.lock()
 	count := 0
-	for i := uint64(0); i < m.size(); i++ {
-		slot := m.items[i].mapSlot
+	for i := uint64(0); i < stor.size(); i++ {
+		slot := &stor.items[i]
 		if slot.isSet != isSet_set {
 			continue
 		}
 		count++
 	}
 
+	m.unlock()
 	if count != m.Len() {
 		return fmt.Errorf("count != m.Len(): %v %v", count, m.Len())
 	}
-	m.unlock()
 
-	for i := uint64(0); i < m.size(); i++ {
-		slot := m.items[i].mapSlot
+	for i := uint64(0); i < stor.size(); i++ {
+		slot := &stor.items[i]
 		if slot.IsSet() != isSet_set {
 			continue
 		}
+		if !m.isEqualKey(slot.entry.key, slot.entry.key) {
+			// a NaN key (see hasher.FloatKeysGoCompatible) can't be found
+			continue
+		}
 
-		foundValue, err := m.Get(slot.key)
-		if foundValue != slot.value || err != nil {
-			hashValue := hasher.Hash(slot.key)
-			expectedIdxValue := m.getIdx(hashValue)
-			return fmt.Errorf("m.Get(slot.key) != slot.value: %v(%v) %v; i:%v key:%v fastkey:%v,%v expectedIdx:%v", foundValue, err, slot.value, i, slot.key, slot.fastKey, slot.fastKeyType, expectedIdxValue)
+		foundValue, err := m.Get(slot.entry.key)
+		if err != nil || !isEqualValue(foundValue, slot.entry.getValue()) {
+			hashValue := m.Hash(slot.entry.key)
+			expectedIdxValue := stor.getIdx(hashValue)
+			return fmt.Errorf("m.Get(slot.key) != slot.value: %v(%v) %v; i:%v key:%v fastkey:%v,%v expectedIdx:%v", foundValue, err, slot.entry.value, i, slot.entry.key, slot.fastKey, slot.fastKeyType, expectedIdxValue)
 		}
 	}
 	return nil
 }
 
+// HasKey reports whether the key is present in the map.
 func (m *openAddressGrowingMap) HasKey(key Key) bool {
-	hashValue := hasher.Hash(key)
-	idxValue := m.getIdx(hashValue)
+	if m.BusySlots() == 0 {
+		return false
+	}
 
-	return m.items[idxValue].mapSlot.IsSet() == isSet_set
+	fastKey, fastKeyType, hashValue, err := m.hashKey(key)
+	if err != nil {
+		return false
+	}
+	slot := m.getSlotByHashValue(fastKey, fastKeyType, hashValue, func(slot *mapSlot) bool {
+		return m.isEqualKey(slot.entry.key, key)
+	})
+	if slot == nil {
+		return false
+	}
+	if m.threadSafety {
+		slot.decreaseReaders()
+	}
+	return true
+}
+
+// Range calls f sequentially for each key and value present in the map.
+// If f returns false, Range stops the iteration.
+//
+// Range doesn't copy the content of the map and doesn't lock it. A slot is
+// read under its readers counter (so the key and the value passed to f are
+// always from the same completed Set()), and the counter is released before
+// f is called, so f may call any method of the map (including Set() or
+// Unset() of the same key).
+//
+// If you're using Range() concurrently with Set() then keep in mind:
+//   - Range() walks the storage which was actual when Range() was called
+//     (an unfinished resize is finished first). If the map is resized
+//     meanwhile, the keys set after the resize are not visited (they are
+//     placed only to the new storage) and the values updated after the resize
+//     may be seen as the old ones.
+//   - A key which is being set or unset concurrently may be visited or may be
+//     not; a value which is being updated concurrently may be the old one or
+//     the new one.
+//   - A key which is present during the whole Range() call is visited exactly
+//     once.
+func (m *openAddressGrowingMap) Range(f func(key Key, value interface{}) bool) {
+	if m.BusySlots() == 0 {
+		return
+	}
+
+	stor := m.loadSettledStorage()
+	for idxValue := uint64(0); idxValue < stor.size(); idxValue++ {
+		key, value, ok := m.readSlot(&stor.items[idxValue])
+		if !ok {
+			continue
+		}
+		if !f(key, value) {
+			return
+		}
+	}
+}
+
+// readSlot reads the key and the value of a slot while iterating over a
+// storage (see hashTable.visitSlot). ok is false if there's no key in the
+// slot.
+func (m *openAddressGrowingMap) readSlot(slot *mapSlot) (key Key, value interface{}, ok bool) {
+	ok = m.visitSlot(slot, func(slot *mapSlot) {
+		key, value = slot.entry.key, slot.entry.getValue()
+	})
+	return
 }
 
 // Keys() returns a slice that contains all keys.
@@ -727,22 +749,13 @@ func (m *openAddressGrowingMap) HasKey(key Key) bool {
 func (m *openAddressGrowingMap) Keys() []interface{} {
 	r := make([]interface{}, 0, m.BusySlots())
 
-	for idxValue := uint64(0); idxValue < m.size(); idxValue++ {
-		slot := &m.items[idxValue].mapSlot
-		if m.threadSafety {
-			switch slot.increaseReaders() {
-			case isSet_notSet, isSet_removed:
-				continue
-			}
-		} else {
-			if slot.IsSet() != isSet_set {
-				continue
-			}
-		}
-		r = append(r, slot.key)
-		if m.threadSafety {
-			slot.decreaseReaders()
+	stor := m.loadSettledStorage()
+	for idxValue := uint64(0); idxValue < stor.size(); idxValue++ {
+		key, _, ok := m.readSlot(&stor.items[idxValue])
+		if !ok {
+			continue
 		}
+		r = append(r, key)
 	}
 
 	return r
@@ -760,26 +773,17 @@ func (m *openAddressGrowingMap) ToSTDMap() map[Key]interface{} {
 	}
 	//m.increaseConcurrency()
 
-	for idxValue := uint64(0); idxValue < m.size(); idxValue++ {
-		slot := &m.items[idxValue].mapSlot
-		if m.threadSafety {
-			switch slot.increaseReaders() {
-			case isSet_notSet, isSet_removed:
-				continue
-			}
-		} else {
-			if slot.IsSet() != isSet_set {
-				continue
-			}
+	stor := m.loadSettledStorage()
+	for idxValue := uint64(0); idxValue < stor.size(); idxValue++ {
+		key, value, ok := m.readSlot(&stor.items[idxValue])
+		if !ok {
+			continue
 		}
-		switch key := slot.key.(type) {
+		switch key := key.(type) {
 		case []byte:
-			r[string(key)] = slot.value
+			r[string(key)] = value
 		default:
-			r[slot.key] = slot.value
-		}
-		if m.threadSafety {
-			slot.decreaseReaders()
+			r[key] = value
 		}
 	}
 
@@ -788,7 +792,7 @@ func (m *openAddressGrowingMap) ToSTDMap() map[Key]interface{} {
 }
 
 func (m *openAddressGrowingMap) FromSTDMap(stdMap map[Key]interface{}) {
-	expectedSize := uint64(float64(len(stdMap))/growAtFullness) + 1
+	expectedSize := uint64(float64(len(stdMap))/m.growAtFullness) + 1
 	if expectedSize > m.initialSize {
 		if err := m.growTo(powerOfTwoGE(expectedSize)); err != nil {
 			panic(err)
@@ -12,6 +12,18 @@ func TestMap(t *testing.T) {
 	benchmark.DoTest(t, newWithArgsIface)
 }
 
+func TestMapConcurrency(t *testing.T) {
+	benchmark.DoTestConcurrency(t, newWithArgsIface)
+}
+
+func TestMapConcurrencyWithUnset(t *testing.T) {
+	benchmark.DoTestConcurrencyWithUnset(t, newWithArgsIface)
+}
+
+func TestMapKeyTypes(t *testing.T) {
+	benchmark.DoTestKeyTypes(t, newWithArgsIface)
+}
+
 func Benchmark_atomicmap_Set_intKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 128, 16, "int")
 }
@@ -28,6 +40,30 @@ func BenchmarkParallel_atomicmap_Set_stringKeyType_blockSize128_keyAmount16_true
 	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 128, 16, "string")
 }
 
+func Benchmark_atomicmap_Set_sliceKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 128, 16, "slice")
+}
+
+func BenchmarkParallel_atomicmap_Set_sliceKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 128, 16, "slice")
+}
+
+func Benchmark_atomicmap_Set_mapKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 128, 16, "map")
+}
+
+func BenchmarkParallel_atomicmap_Set_mapKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 128, 16, "map")
+}
+
+func Benchmark_atomicmap_Set_structKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 128, 16, "struct")
+}
+
+func BenchmarkParallel_atomicmap_Set_structKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 128, 16, "struct")
+}
+
 func Benchmark_atomicmap_Set_intKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 1024, 16, "int")
 }
@@ -44,6 +80,30 @@ func BenchmarkParallel_atomicmap_Set_stringKeyType_blockSize1024_keyAmount16_tru
 	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 1024, 16, "string")
 }
 
+func Benchmark_atomicmap_Set_sliceKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 1024, 16, "slice")
+}
+
+func BenchmarkParallel_atomicmap_Set_sliceKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 1024, 16, "slice")
+}
+
+func Benchmark_atomicmap_Set_mapKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 1024, 16, "map")
+}
+
+func BenchmarkParallel_atomicmap_Set_mapKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 1024, 16, "map")
+}
+
+func Benchmark_atomicmap_Set_structKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 1024, 16, "struct")
+}
+
+func BenchmarkParallel_atomicmap_Set_structKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 1024, 16, "struct")
+}
+
 func Benchmark_atomicmap_Set_intKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 65536, 512, "int")
 }
@@ -60,6 +120,30 @@ func BenchmarkParallel_atomicmap_Set_stringKeyType_blockSize65536_keyAmount512_t
 	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 65536, 512, "string")
 }
 
+func Benchmark_atomicmap_Set_sliceKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 65536, 512, "slice")
+}
+
+func BenchmarkParallel_atomicmap_Set_sliceKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 65536, 512, "slice")
+}
+
+func Benchmark_atomicmap_Set_mapKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 65536, 512, "map")
+}
+
+func BenchmarkParallel_atomicmap_Set_mapKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 65536, 512, "map")
+}
+
+func Benchmark_atomicmap_Set_structKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 65536, 512, "struct")
+}
+
+func BenchmarkParallel_atomicmap_Set_structKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 65536, 512, "struct")
+}
+
 func Benchmark_atomicmap_Set_intKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 4194304, 65536, "int")
 }
@@ -76,6 +160,30 @@ func BenchmarkParallel_atomicmap_Set_stringKeyType_blockSize4194304_keyAmount655
 	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 4194304, 65536, "string")
 }
 
+func Benchmark_atomicmap_Set_sliceKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 4194304, 65536, "slice")
+}
+
+func BenchmarkParallel_atomicmap_Set_sliceKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 4194304, 65536, "slice")
+}
+
+func Benchmark_atomicmap_Set_mapKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 4194304, 65536, "map")
+}
+
+func BenchmarkParallel_atomicmap_Set_mapKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 4194304, 65536, "map")
+}
+
+func Benchmark_atomicmap_Set_structKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 4194304, 65536, "struct")
+}
+
+func BenchmarkParallel_atomicmap_Set_structKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 4194304, 65536, "struct")
+}
+
 func Benchmark_atomicmap_Set_intKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 16777216, 65536, "int")
 }
@@ -92,6 +200,30 @@ func BenchmarkParallel_atomicmap_Set_stringKeyType_blockSize16777216_keyAmount65
 	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 16777216, 65536, "string")
 }
 
+func Benchmark_atomicmap_Set_sliceKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 16777216, 65536, "slice")
+}
+
+func BenchmarkParallel_atomicmap_Set_sliceKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 16777216, 65536, "slice")
+}
+
+func Benchmark_atomicmap_Set_mapKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 16777216, 65536, "map")
+}
+
+func BenchmarkParallel_atomicmap_Set_mapKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 16777216, 65536, "map")
+}
+
+func Benchmark_atomicmap_Set_structKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 16777216, 65536, "struct")
+}
+
+func BenchmarkParallel_atomicmap_Set_structKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 16777216, 65536, "struct")
+}
+
 func Benchmark_atomicmap_Set_intKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 16777216, 1048576, "int")
 }
@@ -108,6 +240,30 @@ func BenchmarkParallel_atomicmap_Set_stringKeyType_blockSize16777216_keyAmount10
 	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 16777216, 1048576, "string")
 }
 
+func Benchmark_atomicmap_Set_sliceKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 16777216, 1048576, "slice")
+}
+
+func BenchmarkParallel_atomicmap_Set_sliceKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 16777216, 1048576, "slice")
+}
+
+func Benchmark_atomicmap_Set_mapKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 16777216, 1048576, "map")
+}
+
+func BenchmarkParallel_atomicmap_Set_mapKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 16777216, 1048576, "map")
+}
+
+func Benchmark_atomicmap_Set_structKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfSet(b, newWithArgsIface, 16777216, 1048576, "struct")
+}
+
+func BenchmarkParallel_atomicmap_Set_structKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfSet(b, newWithArgsIface, 16777216, 1048576, "struct")
+}
+
 func Benchmark_atomicmap_Get_intKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 128, 16, "int")
 }
@@ -124,6 +280,30 @@ func BenchmarkParallel_atomicmap_Get_stringKeyType_blockSize128_keyAmount16_true
 	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 128, 16, "string")
 }
 
+func Benchmark_atomicmap_Get_sliceKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 128, 16, "slice")
+}
+
+func BenchmarkParallel_atomicmap_Get_sliceKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 128, 16, "slice")
+}
+
+func Benchmark_atomicmap_Get_mapKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 128, 16, "map")
+}
+
+func BenchmarkParallel_atomicmap_Get_mapKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 128, 16, "map")
+}
+
+func Benchmark_atomicmap_Get_structKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 128, 16, "struct")
+}
+
+func BenchmarkParallel_atomicmap_Get_structKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 128, 16, "struct")
+}
+
 func Benchmark_atomicmap_Get_intKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 1024, 16, "int")
 }
@@ -140,6 +320,30 @@ func BenchmarkParallel_atomicmap_Get_stringKeyType_blockSize1024_keyAmount16_tru
 	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 1024, 16, "string")
 }
 
+func Benchmark_atomicmap_Get_sliceKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 1024, 16, "slice")
+}
+
+func BenchmarkParallel_atomicmap_Get_sliceKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 1024, 16, "slice")
+}
+
+func Benchmark_atomicmap_Get_mapKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 1024, 16, "map")
+}
+
+func BenchmarkParallel_atomicmap_Get_mapKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 1024, 16, "map")
+}
+
+func Benchmark_atomicmap_Get_structKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 1024, 16, "struct")
+}
+
+func BenchmarkParallel_atomicmap_Get_structKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 1024, 16, "struct")
+}
+
 func Benchmark_atomicmap_Get_intKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 65536, 512, "int")
 }
@@ -156,6 +360,30 @@ func BenchmarkParallel_atomicmap_Get_stringKeyType_blockSize65536_keyAmount512_t
 	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 65536, 512, "string")
 }
 
+func Benchmark_atomicmap_Get_sliceKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 65536, 512, "slice")
+}
+
+func BenchmarkParallel_atomicmap_Get_sliceKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 65536, 512, "slice")
+}
+
+func Benchmark_atomicmap_Get_mapKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 65536, 512, "map")
+}
+
+func BenchmarkParallel_atomicmap_Get_mapKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 65536, 512, "map")
+}
+
+func Benchmark_atomicmap_Get_structKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 65536, 512, "struct")
+}
+
+func BenchmarkParallel_atomicmap_Get_structKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 65536, 512, "struct")
+}
+
 func Benchmark_atomicmap_Get_intKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 4194304, 65536, "int")
 }
@@ -172,6 +400,30 @@ func BenchmarkParallel_atomicmap_Get_stringKeyType_blockSize4194304_keyAmount655
 	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 4194304, 65536, "string")
 }
 
+func Benchmark_atomicmap_Get_sliceKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 4194304, 65536, "slice")
+}
+
+func BenchmarkParallel_atomicmap_Get_sliceKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 4194304, 65536, "slice")
+}
+
+func Benchmark_atomicmap_Get_mapKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 4194304, 65536, "map")
+}
+
+func BenchmarkParallel_atomicmap_Get_mapKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 4194304, 65536, "map")
+}
+
+func Benchmark_atomicmap_Get_structKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 4194304, 65536, "struct")
+}
+
+func BenchmarkParallel_atomicmap_Get_structKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 4194304, 65536, "struct")
+}
+
 func Benchmark_atomicmap_Get_intKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 16777216, 65536, "int")
 }
@@ -188,6 +440,30 @@ func BenchmarkParallel_atomicmap_Get_stringKeyType_blockSize16777216_keyAmount65
 	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 16777216, 65536, "string")
 }
 
+func Benchmark_atomicmap_Get_sliceKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 16777216, 65536, "slice")
+}
+
+func BenchmarkParallel_atomicmap_Get_sliceKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 16777216, 65536, "slice")
+}
+
+func Benchmark_atomicmap_Get_mapKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 16777216, 65536, "map")
+}
+
+func BenchmarkParallel_atomicmap_Get_mapKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 16777216, 65536, "map")
+}
+
+func Benchmark_atomicmap_Get_structKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 16777216, 65536, "struct")
+}
+
+func BenchmarkParallel_atomicmap_Get_structKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 16777216, 65536, "struct")
+}
+
 func Benchmark_atomicmap_Get_intKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 16777216, 1048576, "int")
 }
@@ -204,6 +480,30 @@ func BenchmarkParallel_atomicmap_Get_stringKeyType_blockSize16777216_keyAmount10
 	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 16777216, 1048576, "string")
 }
 
+func Benchmark_atomicmap_Get_sliceKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 16777216, 1048576, "slice")
+}
+
+func BenchmarkParallel_atomicmap_Get_sliceKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 16777216, 1048576, "slice")
+}
+
+func Benchmark_atomicmap_Get_mapKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 16777216, 1048576, "map")
+}
+
+func BenchmarkParallel_atomicmap_Get_mapKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 16777216, 1048576, "map")
+}
+
+func Benchmark_atomicmap_Get_structKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfGet(b, newWithArgsIface, 16777216, 1048576, "struct")
+}
+
+func BenchmarkParallel_atomicmap_Get_structKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoParallelBenchmarkOfGet(b, newWithArgsIface, 16777216, 1048576, "struct")
+}
+
 func Benchmark_atomicmap_Unset_intKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 128, 16, "int")
 }
@@ -212,6 +512,18 @@ func Benchmark_atomicmap_Unset_stringKeyType_blockSize128_keyAmount16_trueThread
 	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 128, 16, "string")
 }
 
+func Benchmark_atomicmap_Unset_sliceKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 128, 16, "slice")
+}
+
+func Benchmark_atomicmap_Unset_mapKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 128, 16, "map")
+}
+
+func Benchmark_atomicmap_Unset_structKeyType_blockSize128_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 128, 16, "struct")
+}
+
 func Benchmark_atomicmap_Unset_intKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 1024, 16, "int")
 }
@@ -220,6 +532,18 @@ func Benchmark_atomicmap_Unset_stringKeyType_blockSize1024_keyAmount16_trueThrea
 	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 1024, 16, "string")
 }
 
+func Benchmark_atomicmap_Unset_sliceKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 1024, 16, "slice")
+}
+
+func Benchmark_atomicmap_Unset_mapKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 1024, 16, "map")
+}
+
+func Benchmark_atomicmap_Unset_structKeyType_blockSize1024_keyAmount16_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 1024, 16, "struct")
+}
+
 func Benchmark_atomicmap_Unset_intKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 65536, 512, "int")
 }
@@ -228,6 +552,18 @@ func Benchmark_atomicmap_Unset_stringKeyType_blockSize65536_keyAmount512_trueThr
 	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 65536, 512, "string")
 }
 
+func Benchmark_atomicmap_Unset_sliceKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 65536, 512, "slice")
+}
+
+func Benchmark_atomicmap_Unset_mapKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 65536, 512, "map")
+}
+
+func Benchmark_atomicmap_Unset_structKeyType_blockSize65536_keyAmount512_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 65536, 512, "struct")
+}
+
 func Benchmark_atomicmap_Unset_intKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 4194304, 65536, "int")
 }
@@ -236,6 +572,18 @@ func Benchmark_atomicmap_Unset_stringKeyType_blockSize4194304_keyAmount65536_tru
 	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 4194304, 65536, "string")
 }
 
+func Benchmark_atomicmap_Unset_sliceKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 4194304, 65536, "slice")
+}
+
+func Benchmark_atomicmap_Unset_mapKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 4194304, 65536, "map")
+}
+
+func Benchmark_atomicmap_Unset_structKeyType_blockSize4194304_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 4194304, 65536, "struct")
+}
+
 func Benchmark_atomicmap_Unset_intKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 16777216, 65536, "int")
 }
@@ -244,6 +592,18 @@ func Benchmark_atomicmap_Unset_stringKeyType_blockSize16777216_keyAmount65536_tr
 	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 16777216, 65536, "string")
 }
 
+func Benchmark_atomicmap_Unset_sliceKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 16777216, 65536, "slice")
+}
+
+func Benchmark_atomicmap_Unset_mapKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 16777216, 65536, "map")
+}
+
+func Benchmark_atomicmap_Unset_structKeyType_blockSize16777216_keyAmount65536_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 16777216, 65536, "struct")
+}
+
 func Benchmark_atomicmap_Unset_intKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 16777216, 1048576, "int")
 }
@@ -251,3 +611,399 @@ func Benchmark_atomicmap_Unset_intKeyType_blockSize16777216_keyAmount1048576_tru
 func Benchmark_atomicmap_Unset_stringKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
 	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 16777216, 1048576, "string")
 }
+
+func Benchmark_atomicmap_Unset_sliceKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 16777216, 1048576, "slice")
+}
+
+func Benchmark_atomicmap_Unset_mapKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 16777216, 1048576, "map")
+}
+
+func Benchmark_atomicmap_Unset_structKeyType_blockSize16777216_keyAmount1048576_trueThreadSafety(b *testing.B) {
+	benchmark.DoBenchmarkOfUnset(b, newWithArgsIface, 16777216, 1048576, "struct")
+}
+
+func Benchmark_atomicmap_SetByString_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Set", "String")
+}
+
+func Benchmark_atomicmap_SetByBytes_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Set", "Bytes")
+}
+
+func Benchmark_atomicmap_SetByUint64_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Set", "Uint64")
+}
+
+func Benchmark_atomicmap_SetByUintptr_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Set", "Uintptr")
+}
+
+func Benchmark_atomicmap_SetByString_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Set", "String")
+}
+
+func Benchmark_atomicmap_SetByBytes_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Set", "Bytes")
+}
+
+func Benchmark_atomicmap_SetByUint64_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Set", "Uint64")
+}
+
+func Benchmark_atomicmap_SetByUintptr_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Set", "Uintptr")
+}
+
+func Benchmark_atomicmap_SetByString_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Set", "String")
+}
+
+func Benchmark_atomicmap_SetByBytes_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Set", "Bytes")
+}
+
+func Benchmark_atomicmap_SetByUint64_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Set", "Uint64")
+}
+
+func Benchmark_atomicmap_SetByUintptr_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Set", "Uintptr")
+}
+
+func Benchmark_atomicmap_SetByString_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Set", "String")
+}
+
+func Benchmark_atomicmap_SetByBytes_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Set", "Bytes")
+}
+
+func Benchmark_atomicmap_SetByUint64_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Set", "Uint64")
+}
+
+func Benchmark_atomicmap_SetByUintptr_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Set", "Uintptr")
+}
+
+func Benchmark_atomicmap_SetByString_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Set", "String")
+}
+
+func Benchmark_atomicmap_SetByBytes_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Set", "Bytes")
+}
+
+func Benchmark_atomicmap_SetByUint64_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Set", "Uint64")
+}
+
+func Benchmark_atomicmap_SetByUintptr_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Set", "Uintptr")
+}
+
+func Benchmark_atomicmap_SetByString_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Set", "String")
+}
+
+func Benchmark_atomicmap_SetByBytes_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Set", "Bytes")
+}
+
+func Benchmark_atomicmap_SetByUint64_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Set", "Uint64")
+}
+
+func Benchmark_atomicmap_SetByUintptr_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Set", "Uintptr")
+}
+
+func Benchmark_atomicmap_GetByString_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Get", "String")
+}
+
+func Benchmark_atomicmap_GetByBytes_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Get", "Bytes")
+}
+
+func Benchmark_atomicmap_GetByUint64_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Get", "Uint64")
+}
+
+func Benchmark_atomicmap_GetByUintptr_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Get", "Uintptr")
+}
+
+func Benchmark_atomicmap_GetByString_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Get", "String")
+}
+
+func Benchmark_atomicmap_GetByBytes_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Get", "Bytes")
+}
+
+func Benchmark_atomicmap_GetByUint64_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Get", "Uint64")
+}
+
+func Benchmark_atomicmap_GetByUintptr_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Get", "Uintptr")
+}
+
+func Benchmark_atomicmap_GetByString_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Get", "String")
+}
+
+func Benchmark_atomicmap_GetByBytes_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Get", "Bytes")
+}
+
+func Benchmark_atomicmap_GetByUint64_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Get", "Uint64")
+}
+
+func Benchmark_atomicmap_GetByUintptr_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Get", "Uintptr")
+}
+
+func Benchmark_atomicmap_GetByString_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Get", "String")
+}
+
+func Benchmark_atomicmap_GetByBytes_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Get", "Bytes")
+}
+
+func Benchmark_atomicmap_GetByUint64_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Get", "Uint64")
+}
+
+func Benchmark_atomicmap_GetByUintptr_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Get", "Uintptr")
+}
+
+func Benchmark_atomicmap_GetByString_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Get", "String")
+}
+
+func Benchmark_atomicmap_GetByBytes_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Get", "Bytes")
+}
+
+func Benchmark_atomicmap_GetByUint64_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Get", "Uint64")
+}
+
+func Benchmark_atomicmap_GetByUintptr_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Get", "Uintptr")
+}
+
+func Benchmark_atomicmap_GetByString_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Get", "String")
+}
+
+func Benchmark_atomicmap_GetByBytes_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Get", "Bytes")
+}
+
+func Benchmark_atomicmap_GetByUint64_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Get", "Uint64")
+}
+
+func Benchmark_atomicmap_GetByUintptr_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Get", "Uintptr")
+}
+
+func Benchmark_atomicmap_SwapByString_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Swap", "String")
+}
+
+func Benchmark_atomicmap_SwapByBytes_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Swap", "Bytes")
+}
+
+func Benchmark_atomicmap_SwapByUint64_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Swap", "Uint64")
+}
+
+func Benchmark_atomicmap_SwapByUintptr_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Swap", "Uintptr")
+}
+
+func Benchmark_atomicmap_SwapByString_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Swap", "String")
+}
+
+func Benchmark_atomicmap_SwapByBytes_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Swap", "Bytes")
+}
+
+func Benchmark_atomicmap_SwapByUint64_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Swap", "Uint64")
+}
+
+func Benchmark_atomicmap_SwapByUintptr_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Swap", "Uintptr")
+}
+
+func Benchmark_atomicmap_SwapByString_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Swap", "String")
+}
+
+func Benchmark_atomicmap_SwapByBytes_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Swap", "Bytes")
+}
+
+func Benchmark_atomicmap_SwapByUint64_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Swap", "Uint64")
+}
+
+func Benchmark_atomicmap_SwapByUintptr_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Swap", "Uintptr")
+}
+
+func Benchmark_atomicmap_SwapByString_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Swap", "String")
+}
+
+func Benchmark_atomicmap_SwapByBytes_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Swap", "Bytes")
+}
+
+func Benchmark_atomicmap_SwapByUint64_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Swap", "Uint64")
+}
+
+func Benchmark_atomicmap_SwapByUintptr_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Swap", "Uintptr")
+}
+
+func Benchmark_atomicmap_SwapByString_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Swap", "String")
+}
+
+func Benchmark_atomicmap_SwapByBytes_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Swap", "Bytes")
+}
+
+func Benchmark_atomicmap_SwapByUint64_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Swap", "Uint64")
+}
+
+func Benchmark_atomicmap_SwapByUintptr_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Swap", "Uintptr")
+}
+
+func Benchmark_atomicmap_SwapByString_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Swap", "String")
+}
+
+func Benchmark_atomicmap_SwapByBytes_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Swap", "Bytes")
+}
+
+func Benchmark_atomicmap_SwapByUint64_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Swap", "Uint64")
+}
+
+func Benchmark_atomicmap_SwapByUintptr_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Swap", "Uintptr")
+}
+
+func Benchmark_atomicmap_UnsetByString_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Unset", "String")
+}
+
+func Benchmark_atomicmap_UnsetByBytes_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Unset", "Bytes")
+}
+
+func Benchmark_atomicmap_UnsetByUint64_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Unset", "Uint64")
+}
+
+func Benchmark_atomicmap_UnsetByUintptr_blockSize128_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 128, 16, "Unset", "Uintptr")
+}
+
+func Benchmark_atomicmap_UnsetByString_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Unset", "String")
+}
+
+func Benchmark_atomicmap_UnsetByBytes_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Unset", "Bytes")
+}
+
+func Benchmark_atomicmap_UnsetByUint64_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Unset", "Uint64")
+}
+
+func Benchmark_atomicmap_UnsetByUintptr_blockSize1024_keyAmount16(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 1024, 16, "Unset", "Uintptr")
+}
+
+func Benchmark_atomicmap_UnsetByString_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Unset", "String")
+}
+
+func Benchmark_atomicmap_UnsetByBytes_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Unset", "Bytes")
+}
+
+func Benchmark_atomicmap_UnsetByUint64_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Unset", "Uint64")
+}
+
+func Benchmark_atomicmap_UnsetByUintptr_blockSize65536_keyAmount512(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 65536, 512, "Unset", "Uintptr")
+}
+
+func Benchmark_atomicmap_UnsetByString_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Unset", "String")
+}
+
+func Benchmark_atomicmap_UnsetByBytes_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Unset", "Bytes")
+}
+
+func Benchmark_atomicmap_UnsetByUint64_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Unset", "Uint64")
+}
+
+func Benchmark_atomicmap_UnsetByUintptr_blockSize4194304_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 4194304, 65536, "Unset", "Uintptr")
+}
+
+func Benchmark_atomicmap_UnsetByString_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Unset", "String")
+}
+
+func Benchmark_atomicmap_UnsetByBytes_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Unset", "Bytes")
+}
+
+func Benchmark_atomicmap_UnsetByUint64_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Unset", "Uint64")
+}
+
+func Benchmark_atomicmap_UnsetByUintptr_blockSize16777216_keyAmount65536(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 65536, "Unset", "Uintptr")
+}
+
+func Benchmark_atomicmap_UnsetByString_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Unset", "String")
+}
+
+func Benchmark_atomicmap_UnsetByBytes_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Unset", "Bytes")
+}
+
+func Benchmark_atomicmap_UnsetByUint64_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Unset", "Uint64")
+}
+
+func Benchmark_atomicmap_UnsetByUintptr_blockSize16777216_keyAmount1048576(b *testing.B) {
+	benchmark.DoBenchmarkOfTypedKeyMethod(b, newWithArgsIface, 16777216, 1048576, "Unset", "Uintptr")
+}
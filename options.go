@@ -0,0 +1,309 @@
+package atomicmap
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+
+	"github.com/xaionaro-go/atomicmap/hasher"
+	I "github.com/xaionaro-go/atomicmap/interfaces"
+)
+
+const (
+	defaultGrowAtFullness = 0.85
+	defaultGrowthFactor   = 2
+)
+
+// Logger is used to report the adjustments of the options (like a block size
+// which is not a power of 2). *log.Logger implements it.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// Option configures a map constructed by NewWithOptions or
+// NewTypedWithOptions. An option returns an error (wrapping InvalidOption)
+// if the passed value is not valid.
+type Option func(*options) error
+
+type options struct {
+	blockSize        uint64
+	growAtFullness   float64
+	growthFactor     uint64
+	maxSize          uint64
+	shrinkAtFullness float64
+	threadSafety     bool
+	forbidGrowing    bool
+	hasher           I.Hasher // nil means the default hasher
+	floatKeyPolicy   hasher.FloatKeyPolicy
+	unifiedNumbers   bool
+	keyType          reflect.Type // nil means any key types
+	logger           Logger
+}
+
+func defaultOptions() options {
+	return options{
+		blockSize:      defaultBlockSize,
+		growAtFullness: defaultGrowAtFullness,
+		growthFactor:   defaultGrowthFactor,
+		maxSize:        maximalSize,
+		threadSafety:   true,
+		logger:         log.Default(),
+	}
+}
+
+// newOptions applies the options to the defaults. The options depending on
+// each other (like the shrink fullness and the load factor) are checked
+// here, after all the options are applied, so their order doesn't matter.
+func newOptions(opts []Option) (options, error) {
+	result := defaultOptions()
+	for _, opt := range opts {
+		if err := opt(&result); err != nil {
+			return result, err
+		}
+	}
+	if err := result.applyKeyPolicy(); err != nil {
+		return result, err
+	}
+	if result.shrinkAtFullness >= result.growAtFullness {
+		return result, fmt.Errorf("%w: the shrink fullness (%v) should be less than the load factor (%v)", InvalidOption, result.shrinkAtFullness, result.growAtFullness)
+	}
+	result.blockSize = fixBlockSize(result.blockSize, result.logger)
+	if result.blockSize > result.maxSize {
+		return result, fmt.Errorf("%w: the block size (%v) is greater than the maximal size (%v)", InvalidOption, result.blockSize, result.maxSize)
+	}
+	return result, nil
+}
+
+// applyKeyPolicy replaces the hasher with one using the float key policy
+// and the unified numeric keys or with one pinned to the key type. It's
+// applied after all the options, since WithHasher and WithRandomSeed may go
+// after WithFloatKeyPolicy, WithUnifiedNumericKeys and WithKeyType.
+func (opts *options) applyKeyPolicy() error {
+	if opts.keyType != nil {
+		return opts.applyKeyType()
+	}
+	if opts.floatKeyPolicy == hasher.FloatKeysGoCompatible && !opts.unifiedNumbers {
+		return nil
+	}
+	switch h := opts.hasher.(type) {
+	case nil, *hasher.Hasher:
+		newHasher := hasher.NewWithFloatKeyPolicy(opts.floatKeyPolicy)
+		if opts.unifiedNumbers {
+			newHasher = newHasher.WithUnifiedNumericKeys()
+		}
+		opts.hasher = newHasher
+	case *hasher.SeededHasher:
+		newHasher := h.WithFloatKeyPolicy(opts.floatKeyPolicy)
+		if opts.unifiedNumbers {
+			newHasher = newHasher.WithUnifiedNumericKeys()
+		}
+		opts.hasher = newHasher
+	default:
+		return fmt.Errorf("%w: a key policy (float key policy %v, unified numeric keys: %v) can't be applied to a custom hasher", InvalidOption, opts.floatKeyPolicy, opts.unifiedNumbers)
+	}
+	return nil
+}
+
+func (opts *options) applyKeyType() error {
+	if opts.floatKeyPolicy != hasher.FloatKeysGoCompatible || opts.unifiedNumbers {
+		return fmt.Errorf("%w: the key type can't be pinned together with a float key policy or the unified numeric keys", InvalidOption)
+	}
+	var newHasher *hasher.StrictHasher
+	var err error
+	switch h := opts.hasher.(type) {
+	case nil, *hasher.Hasher:
+		newHasher, err = hasher.NewStrict(opts.keyType)
+	case *hasher.SeededHasher:
+		newHasher, err = h.Strict(opts.keyType)
+	default:
+		return fmt.Errorf("%w: the key type can't be pinned with a custom hasher", InvalidOption)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", InvalidOption, err)
+	}
+	opts.hasher = newHasher
+	return nil
+}
+
+// setupMapControl applies the options related to resizing and
+// synchronization. The forbidGrowing option should be applied separately,
+// after the initial storage is allocated.
+func (opts *options) setupMapControl(m *mapControl) {
+	m.threadSafety = opts.threadSafety
+	m.growAtFullness = opts.growAtFullness
+	m.growthFactor = opts.growthFactor
+	m.maxSize = opts.maxSize
+	m.shrinkAtFullness = opts.shrinkAtFullness
+}
+
+// WithBlockSize sets the initial size of the storage. It should be greater
+// than the maximal amount of elements you're planning to store (to avoid
+// growing). A value which is not a power of 2 is rounded up to a power of 2.
+// It should not be greater than 2^32.
+func WithBlockSize(blockSize uint64) Option {
+	return func(opts *options) error {
+		if blockSize == 0 || blockSize > maximalSize {
+			return fmt.Errorf("%w: the block size should be in range [1, %v], got %v", InvalidOption, uint64(maximalSize), blockSize)
+		}
+		opts.blockSize = blockSize
+		return nil
+	}
+}
+
+// WithLoadFactor sets the fullness (the amount of occupied slots divided by
+// the size of the storage) at which the storage is grown. It should be in
+// range (0, 1). The default is 0.85.
+func WithLoadFactor(loadFactor float64) Option {
+	return func(opts *options) error {
+		if math.IsNaN(loadFactor) || loadFactor <= 0 || loadFactor >= 1 {
+			return fmt.Errorf("%w: the load factor should be in range (0, 1), got %v", InvalidOption, loadFactor)
+		}
+		opts.growAtFullness = loadFactor
+		return nil
+	}
+}
+
+// WithGrowthFactor sets how many times the storage is enlarged when it's
+// grown. It should be a power of 2 not less than 2. The default is 2.
+func WithGrowthFactor(growthFactor uint64) Option {
+	return func(opts *options) error {
+		if growthFactor < 2 || !isPowerOfTwo(growthFactor) {
+			return fmt.Errorf("%w: the growth factor should be a power of 2 not less than 2, got %v", InvalidOption, growthFactor)
+		}
+		opts.growthFactor = growthFactor
+		return nil
+	}
+}
+
+// WithMaxSize sets the maximal size of the storage: the map returns
+// NoSpaceLeft instead of growing beyond it. It should be a power of 2 not
+// greater than 2^32 (which is also the default).
+func WithMaxSize(maxSize uint64) Option {
+	return func(opts *options) error {
+		if !isPowerOfTwo(maxSize) || maxSize > maximalSize {
+			return fmt.Errorf("%w: the maximal size should be a power of 2 not greater than %v, got %v", InvalidOption, uint64(maximalSize), maxSize)
+		}
+		opts.maxSize = maxSize
+		return nil
+	}
+}
+
+// WithShrinkAtFullness enables automatic shrinking of the storage (see
+// SetShrinkAtFullness). It should be in range [0, load factor), see
+// WithLoadFactor. The default is 0 (no automatic shrinking).
+func WithShrinkAtFullness(fullness float64) Option {
+	return func(opts *options) error {
+		if math.IsNaN(fullness) || fullness < 0 {
+			return fmt.Errorf("%w: the shrink fullness should not be negative, got %v", InvalidOption, fullness)
+		}
+		opts.shrinkAtFullness = fullness
+		return nil
+	}
+}
+
+// WithThreadSafety enables or disables the synchronization between
+// concurrent writers (see SetThreadSafety). It's enabled by default.
+func WithThreadSafety(threadSafety bool) Option {
+	return func(opts *options) error {
+		opts.threadSafety = threadSafety
+		return nil
+	}
+}
+
+// WithForbidGrowing forbids the storage to grow (see SetForbidGrowing).
+func WithForbidGrowing(forbidGrowing bool) Option {
+	return func(opts *options) error {
+		opts.forbidGrowing = forbidGrowing
+		return nil
+	}
+}
+
+// WithHasher sets the hasher to be used by the map. It's not supported by
+// Typed.
+func WithHasher(hasher I.Hasher) Option {
+	return func(opts *options) error {
+		if hasher == nil {
+			return fmt.Errorf("%w: the hasher should not be nil", InvalidOption)
+		}
+		opts.hasher = hasher
+		return nil
+	}
+}
+
+// WithRandomSeed makes the map hash the keys with a random seed of its own
+// (see hasher.SeededHasher), so the keys colliding in this map can't be
+// predicted. Use it if the keys are controlled by untrusted parties. It's
+// not supported by Typed.
+func WithRandomSeed() Option {
+	return WithHasher(hasher.NewSeeded(hasher.RandomSeed()))
+}
+
+// WithProcessSeed is the same as WithRandomSeed, but the seed is shared by
+// all the maps of the process (see hasher.ProcessSeed).
+func WithProcessSeed() Option {
+	return WithHasher(hasher.NewSeeded(hasher.ProcessSeed()))
+}
+
+// WithFloatKeyPolicy sets which float and complex keys are the same key
+// (see hasher.FloatKeyPolicy). The default is hasher.FloatKeysGoCompatible:
+// the same as in the built-in maps. Only hasher.FloatKeysGoCompatible is
+// supported by Typed, and it can't be combined with a custom hasher.
+func WithFloatKeyPolicy(policy hasher.FloatKeyPolicy) Option {
+	return func(opts *options) error {
+		switch policy {
+		case hasher.FloatKeysGoCompatible, hasher.FloatKeysBitwise:
+		default:
+			return fmt.Errorf("%w: unknown float key policy %d", InvalidOption, policy)
+		}
+		opts.floatKeyPolicy = policy
+		return nil
+	}
+}
+
+// WithUnifiedNumericKeys makes the keys of all the predeclared integer types
+// (int, int64, uint32, uintptr and so on) and the integral floats to be one
+// numeric key: a value set by int64(5) is found by int(5), uint8(5) or
+// float64(5). It's useful if the keys come from JSON decoders or SQL
+// drivers, which may return the same value as different types. The
+// non-integral floats are still distinct keys of their types. It's not
+// supported by Typed and can't be combined with a custom hasher.
+func WithUnifiedNumericKeys() Option {
+	return func(opts *options) error {
+		opts.unifiedNumbers = true
+		return nil
+	}
+}
+
+// WithKeyType pins the type of the keys: the keys of other types are
+// rejected with a *WrongKeyTypeError (errors.Is(err, WrongKeyType) is true
+// for it), and the keys are hashed and compared by functions specialized for
+// the type (see hasher.StrictHasher). For example:
+//
+//	m, err := NewWithOptions(WithKeyType(reflect.TypeOf("")))
+//
+// The supported types are string, []byte, the predeclared integer types and
+// uintptr. It can be combined with WithRandomSeed and WithProcessSeed, but
+// not with WithHasher, WithFloatKeyPolicy and WithUnifiedNumericKeys. It's
+// not supported by Typed (the type of its keys is pinned anyway).
+func WithKeyType(keyType reflect.Type) Option {
+	return func(opts *options) error {
+		if keyType == nil {
+			return fmt.Errorf("%w: the key type should not be nil", InvalidOption)
+		}
+		opts.keyType = keyType
+		return nil
+	}
+}
+
+// WithLogger sets the logger to report the adjustments of the options to.
+// The default is the standard logger of package "log".
+func WithLogger(logger Logger) Option {
+	return func(opts *options) error {
+		if logger == nil {
+			return fmt.Errorf("%w: the logger should not be nil", InvalidOption)
+		}
+		opts.logger = logger
+		return nil
+	}
+}
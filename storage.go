@@ -4,6 +4,7 @@ import (
 	"runtime"
 	"sync/atomic"
 	"time"
+	"unsafe"
 )
 
 type isSet uint32
@@ -14,6 +15,7 @@ const (
 	isSet_setting
 	isSet_updating
 	isSet_removed
+	isSet_moved // the slot is moved to the next storage (see migration.go)
 )
 
 func (i *isSet) Load() isSet {
@@ -24,32 +26,79 @@ func (i *isSet) Store(newValue isSet) {
 	atomic.StoreUint32((*uint32)(i), uint32(newValue))
 }
 
-type storageItem struct {
-	// All the stuff what we need to grow. This variables are not connected
-	// It's just all (unrelated) slices we need united into one to decrease
-	// the number of memory allocations
-
-	mapSlot mapSlot
-	// ...other variables here...
-}
-
-type mapSlot struct {
+// slotState is the part of a slot that implements the slot state machine
+// (notSet -> setting -> set <-> updating -> removed/moved) and the readers
+// counter. It's shared by all the map implementations in this package.
+type slotState struct {
 	isSet        isSet
 	readersCount int32
-	hashValue    uint64
-	slid         uint64 // how much items were already busy so we were have to go forward
+}
+
+type counterType uint8
+
+const (
+	counterType_none = counterType(iota) // 0
+	counterType_uint64
+	counterType_int64
+)
+
+// storageSlot is a slot of the storage. The header (the state, the hash
+// value and the fast key) is handled by hashTable, the entry (the key and the
+// value) is specific to the map implementation (see mapEntry and
+// typedEntry).
+type storageSlot[E any] struct {
+	slotState
+	hashValue   uint64
+	slid        uint64 // how much items were already busy so we were have to go forward
+	fastKey     uint64 // the pre-hash value if it's the full key, see hasher.PreHash
+	fastKeyType uint8  // the type ID of the full key or zero if the key is not full
+	entry       E
+}
+
+// clearKey forgets the key and the value of the slot when it becomes a
+// tombstone. Otherwise a new key reusing the tombstone may inherit the stale
+// fastKey/fastKeyType and be compared as another (full) key.
+func (slot *storageSlot[E]) clearKey() {
+	var zeroEntry E
+	slot.hashValue, slot.fastKey, slot.fastKeyType = 0, 0, 0
+	slot.entry = zeroEntry
+}
+
+// mapEntry is the entry of a slot of Map.
+type mapEntry struct {
+	counterValue uint64 // is used instead of "value" if counterType != counterType_none
 	key          Key
 	bytesValue   []byte
 	value        interface{}
-	fastKey      uint64
-	fastKeyType  uint8
+	counterType  counterType
 }
 
-func (slot *mapSlot) IsSet() isSet {
+func (entry *mapEntry) getValue() interface{} {
+	switch entry.counterType {
+	case counterType_uint64:
+		return atomic.LoadUint64(&entry.counterValue)
+	case counterType_int64:
+		return int64(atomic.LoadUint64(&entry.counterValue))
+	}
+	if entry.bytesValue != nil {
+		return entry.bytesValue
+	}
+	return entry.value
+}
+
+func (entry *mapEntry) setValue(value interface{}) {
+	entry.value = value
+	entry.bytesValue = nil
+	entry.counterType = counterType_none
+}
+
+type mapSlot = storageSlot[mapEntry]
+
+func (slot *slotState) IsSet() isSet {
 	return slot.isSet.Load()
 }
 
-/*func (slot *mapSlot) waitForIsSet() bool {
+/*func (slot *slotState) waitForIsSet() bool {
 	switch slot.IsSet() {
 	case isSet_set:
 		return true
@@ -73,14 +122,15 @@ func (i *isSet) CompareAndSwap(oldV, newV isSet) bool {
 	return atomic.CompareAndSwapUint32((*uint32)(i), uint32(oldV), uint32(newV))
 }
 
-func (slot *mapSlot) setIsUpdating() bool {
+func (slot *slotState) setIsUpdating() bool {
 	if slot.isSet.CompareAndSwap(isSet_set, isSet_updating) {
 		return true
 	}
 
 	runtime.Gosched()
 	for !slot.isSet.CompareAndSwap(isSet_set, isSet_updating) {
-		if slot.IsSet() == isSet_removed {
+		switch slot.IsSet() {
+		case isSet_removed, isSet_moved:
 			return false
 		}
 		time.Sleep(lockSleepInterval)
@@ -88,7 +138,7 @@ func (slot *mapSlot) setIsUpdating() bool {
 	return true
 }
 
-func (slot *mapSlot) waitForReadersOut() {
+func (slot *slotState) waitForReadersOut() {
 	if atomic.LoadInt32(&slot.readersCount) == 0 {
 		return
 	}
@@ -99,13 +149,13 @@ func (slot *mapSlot) waitForReadersOut() {
 	}
 }
 
-func (slot *mapSlot) increaseReaders() isSet {
+func (slot *slotState) increaseReaders() isSet {
 	atomic.AddInt32(&slot.readersCount, 1)
 	isSet := slot.IsSet()
 	switch isSet {
 	case isSet_set:
 		return isSet
-	case isSet_notSet, isSet_removed:
+	case isSet_notSet, isSet_removed, isSet_moved:
 		atomic.AddInt32(&slot.readersCount, -1)
 		return isSet
 	default:
@@ -118,7 +168,7 @@ func (slot *mapSlot) increaseReaders() isSet {
 		switch isSet {
 		case isSet_set:
 			return isSet
-		case isSet_notSet, isSet_removed:
+		case isSet_notSet, isSet_removed, isSet_moved:
 			atomic.AddInt32(&slot.readersCount, -1)
 			return isSet
 		default:
@@ -126,47 +176,53 @@ func (slot *mapSlot) increaseReaders() isSet {
 			time.Sleep(lockSleepInterval)
 		}
 	}
-	panic(`Shouldn't happen`)
-	return isSet_notSet
 }
 
-func (slot *mapSlot) decreaseReaders() {
+// tryIncreaseReaders is the non-blocking version of increaseReaders: the
+// reader is left only if the slot is set.
+func (slot *slotState) tryIncreaseReaders() isSet {
+	atomic.AddInt32(&slot.readersCount, 1)
+	isSet := slot.IsSet()
+	if isSet != isSet_set {
+		atomic.AddInt32(&slot.readersCount, -1)
+	}
+	return isSet
+}
+
+func (slot *slotState) decreaseReaders() {
 	if atomic.AddInt32(&slot.readersCount, -1) < 0 {
 		panic(`Shouldn't happen`)
 	}
 }
 
-type storage struct {
-	items []storageItem
+type storage[E any] struct {
+	items []storageSlot[E]
+
+	// The state of the migration of this storage to the next one (see
+	// migration.go). "next" is nil if there's no migration.
+	next            *storage[E]
+	migrationCursor uint64
+	migratedCount   uint64
 }
 
-func newStorage(size uint64) *storage {
-	return &storage{
-		items: make([]storageItem, size),
+func newStorage[E any](size uint64) *storage[E] {
+	return &storage[E]{
+		items: make([]storageSlot[E], size),
 	}
 }
 
-func (stor *storage) copyOldItemsAfterGrowing(oldStorage *storage) {
-	if oldStorage == nil {
-		return
-	}
-	if len(oldStorage.items) == 0 {
-		return
+func (stor *storage[E]) loadNext() *storage[E] {
+	if stor == nil {
+		return nil
 	}
-	for i := 0; i < len(oldStorage.items); i++ {
-		oldSlot := &oldStorage.items[i].mapSlot
-		if oldSlot.isSet == isSet_notSet {
-			continue
-		}
+	return (*storage[E])(atomic.LoadPointer((*unsafe.Pointer)((unsafe.Pointer)(&stor.next))))
+}
 
-		newIdxValue := stor.getIdx(oldSlot.hashValue)
-		newSlot, _, slid := stor.findFreeSlot(newIdxValue)
-		copySlot(newSlot, oldSlot)
-		newSlot.slid = slid
-	}
+func (stor *storage[E]) storeNext(next *storage[E]) {
+	atomic.StorePointer((*unsafe.Pointer)((unsafe.Pointer)(&stor.next)), (unsafe.Pointer)(next))
 }
 
-func (stor *storage) size() uint64 {
+func (stor *storage[E]) size() uint64 {
 	if stor == nil {
 		return 0
 	}
@@ -177,22 +233,6 @@ func getIdxHashMask(size uint64) uint64 { // this function requires size to be a
 	return size - 1 // example 01000000 -> 00111111
 }
 
-func (stor *storage) getIdx(hashValue uint64) uint64 {
+func (stor *storage[E]) getIdx(hashValue uint64) uint64 {
 	return hashValue & getIdxHashMask(stor.size())
 }
-
-func (stor *storage) findFreeSlot(idxValue uint64) (*mapSlot, uint64, uint64) {
-	var slotCandidate *mapSlot
-	slid := uint64(0)
-	for { // Going forward through the storage while a collision (to find a free slots)
-		slotCandidate = &stor.items[idxValue].mapSlot
-		if slotCandidate.isSet == isSet_notSet {
-			return slotCandidate, idxValue, slid
-		}
-		slid++
-		idxValue++
-		if idxValue >= stor.size() {
-			idxValue = 0
-		}
-	}
-}
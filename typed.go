@@ -0,0 +1,276 @@
+package atomicmap
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/xaionaro-go/atomicmap/hasher"
+)
+
+// Typed is a type-safe variant of Map. It's the same hash table (see
+// hashTable: the storage, the slot state machine and the growing
+// procedure), but keys and values are kept unboxed: there're no interface
+// conversions on Set and no type assertions on Get. The hashing path is
+// chosen once on construction depending on the kind of K.
+type Typed[K comparable, V any] struct {
+	hashTable[typedEntry[K, V]]
+
+	preHash func(K) (uint64, uint8, bool)
+}
+
+// typedEntry is the entry of a slot of Typed.
+type typedEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func NewTyped[K comparable, V any]() *Typed[K, V] {
+	return NewTypedWithArgs[K, V](0)
+}
+
+// blockSize has the same meaning as in NewWithArgs.
+func NewTypedWithArgs[K comparable, V any](blockSize uint64) *Typed[K, V] {
+	var opts []Option
+	if blockSize > 0 {
+		opts = append(opts, WithBlockSize(blockSize))
+	}
+	result, err := NewTypedWithOptions[K, V](opts...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// NewTypedWithOptions is the same as NewWithOptions, but for Typed. Options
+// WithHasher, WithUnifiedNumericKeys and WithKeyType are not supported (the
+// key type defines the hashing), and the float keys are always compared by
+// "==" (hasher.FloatKeysGoCompatible).
+func NewTypedWithOptions[K comparable, V any](opts ...Option) (*Typed[K, V], error) {
+	cfg, err := newOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.floatKeyPolicy != hasher.FloatKeysGoCompatible {
+		return nil, fmt.Errorf("%w: float key policy %v is not supported by Typed", InvalidOption, cfg.floatKeyPolicy)
+	}
+	if cfg.keyType != nil {
+		return nil, fmt.Errorf("%w: the key type of Typed is pinned by its type parameter", InvalidOption)
+	}
+	if cfg.unifiedNumbers {
+		return nil, fmt.Errorf("%w: the unified numeric keys are not supported by Typed", InvalidOption)
+	}
+	if cfg.hasher != nil {
+		return nil, fmt.Errorf("%w: a custom hasher is not supported by Typed", InvalidOption)
+	}
+	result := &Typed[K, V]{preHash: typedPreHashFunc[K]()}
+	if err := result.init(&cfg); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// typedPreHashFunc selects the pre-hash function for the key type once, so
+// Typed doesn't pass keys through the type switch of hasher.PreHash on every
+// operation. The type IDs are the same as in hasher.PreHash.
+func typedPreHashFunc[K comparable]() func(K) (uint64, uint8, bool) {
+	switch reflect.TypeOf((*K)(nil)).Elem().Kind() {
+	case reflect.String:
+		return func(key K) (uint64, uint8, bool) {
+			return hasher.PreHashString(*(*string)(unsafe.Pointer(&key)))
+		}
+	case reflect.Int:
+		return func(key K) (uint64, uint8, bool) {
+			return uint64(*(*int)(unsafe.Pointer(&key))), 3, true
+		}
+	case reflect.Uint:
+		return func(key K) (uint64, uint8, bool) {
+			return uint64(*(*uint)(unsafe.Pointer(&key))), 4, true
+		}
+	case reflect.Int8:
+		return func(key K) (uint64, uint8, bool) {
+			return uint64(*(*int8)(unsafe.Pointer(&key))), 5, true
+		}
+	case reflect.Uint8:
+		return func(key K) (uint64, uint8, bool) {
+			return uint64(*(*uint8)(unsafe.Pointer(&key))), 6, true
+		}
+	case reflect.Int16:
+		return func(key K) (uint64, uint8, bool) {
+			return uint64(*(*int16)(unsafe.Pointer(&key))), 7, true
+		}
+	case reflect.Uint16:
+		return func(key K) (uint64, uint8, bool) {
+			return uint64(*(*uint16)(unsafe.Pointer(&key))), 8, true
+		}
+	case reflect.Int32:
+		return func(key K) (uint64, uint8, bool) {
+			return uint64(*(*int32)(unsafe.Pointer(&key))), 9, true
+		}
+	case reflect.Uint32:
+		return func(key K) (uint64, uint8, bool) {
+			return uint64(*(*uint32)(unsafe.Pointer(&key))), 10, true
+		}
+	case reflect.Int64:
+		return func(key K) (uint64, uint8, bool) {
+			return uint64(*(*int64)(unsafe.Pointer(&key))), 11, true
+		}
+	case reflect.Uint64:
+		return func(key K) (uint64, uint8, bool) {
+			return hasher.PreHashUint64(*(*uint64)(unsafe.Pointer(&key)))
+		}
+	case reflect.Float32:
+		return func(key K) (uint64, uint8, bool) {
+			return hasher.PreHashFloat32(*(*float32)(unsafe.Pointer(&key)))
+		}
+	case reflect.Float64:
+		return func(key K) (uint64, uint8, bool) {
+			return hasher.PreHashFloat64(*(*float64)(unsafe.Pointer(&key)))
+		}
+	case reflect.Uintptr:
+		return func(key K) (uint64, uint8, bool) {
+			return hasher.PreHashUintptr(*(*uintptr)(unsafe.Pointer(&key)))
+		}
+	}
+	return func(key K) (uint64, uint8, bool) {
+		return hasher.PreHash(key)
+	}
+}
+
+func (m *Typed[K, V]) Set(key K, value V) error {
+	_, _, err := m.swap(key, value)
+	return err
+}
+
+// Swap sets the value and returns the previous one (or the zero value of V
+// if there was no such key).
+func (m *Typed[K, V]) Swap(key K, value V) (oldValue V, err error) {
+	oldValue, _, err = m.swap(key, value)
+	return
+}
+
+// hash returns the pre-hash of the key (see hasher.PreHash) and the hash
+// value. typeID is zero if the pre-hash is not full (as hashTable expects).
+func (m *Typed[K, V]) hash(key K) (preHashValue uint64, typeID uint8, preHashValueIsFull bool, hashValue uint64) {
+	preHashValue, typeID, preHashValueIsFull = m.preHash(key)
+	hashValue = hasher.CompleteHash(preHashValue, typeID)
+	if !preHashValueIsFull {
+		typeID = 0
+	}
+	return
+}
+
+func (m *Typed[K, V]) swap(key K, value V) (oldValue V, isUpdated bool, err error) {
+	preHashValue, typeID, preHashValueIsFull, hashValue := m.hash(key)
+	isNewSlot := false
+	err = m.setHashed(preHashValue, typeID, preHashValueIsFull, hashValue, func(slot *storageSlot[typedEntry[K, V]]) bool {
+		return slot.entry.key == key
+	}, func(slot *storageSlot[typedEntry[K, V]]) {
+		slot.entry.key = key
+		isNewSlot = true
+	}, func(slot *storageSlot[typedEntry[K, V]]) bool {
+		if !isNewSlot {
+			oldValue, isUpdated = slot.entry.value, true
+		}
+		slot.entry.value = value
+		return true
+	})
+	return
+}
+
+// Get returns the value of the key or NotFound if there's no such key. The
+// same guarantees as for Map.Get are applicable: a Get racing with a resize
+// never returns a spurious NotFound.
+func (m *Typed[K, V]) Get(key K) (value V, err error) {
+	if m.BusySlots() == 0 {
+		return value, NotFound
+	}
+
+	preHashValue, typeID, _, hashValue := m.hash(key)
+	slot := m.getSlotByHashValue(preHashValue, typeID, hashValue, func(slot *storageSlot[typedEntry[K, V]]) bool {
+		return slot.entry.key == key
+	})
+	if slot == nil {
+		return value, NotFound
+	}
+	value = slot.entry.value
+	if m.threadSafety {
+		slot.decreaseReaders()
+	}
+	return value, nil
+}
+
+func (m *Typed[K, V]) Unset(key K) error {
+	return m.UnsetIf(key, nil)
+}
+
+// UnsetIf removes the key only if conditionFunc returns true for the value
+// (or if conditionFunc is nil).
+func (m *Typed[K, V]) UnsetIf(key K, conditionFunc func(value V) bool) error {
+	err := m.unsetIf(key, conditionFunc)
+	if err == nil {
+		m.shrinkIfNeeded()
+	}
+	return err
+}
+
+func (m *Typed[K, V]) unsetIf(key K, conditionFunc func(value V) bool) error {
+	var isRightValue func(*storageSlot[typedEntry[K, V]]) bool
+	if conditionFunc != nil {
+		isRightValue = func(slot *storageSlot[typedEntry[K, V]]) bool {
+			return conditionFunc(slot.entry.value)
+		}
+	}
+	return m.unset(func() *storageSlot[typedEntry[K, V]] {
+		preHashValue, typeID, _, hashValue := m.hash(key)
+		slot, _ := m.lockSlotByHashValue(preHashValue, typeID, hashValue, func(slot *storageSlot[typedEntry[K, V]]) bool {
+			return slot.entry.key == key
+		})
+		return slot
+	}, isRightValue)
+}
+
+func (m *Typed[K, V]) Len() int {
+	if m == nil {
+		return 0
+	}
+	return int(atomic.LoadInt64(&m.busySlots))
+}
+func (m *Typed[K, V]) BusySlots() uint64 {
+	if m == nil {
+		return 0
+	}
+	return uint64(atomic.LoadInt64(&m.busySlots))
+}
+
+// ToSTDMap converts to a standart map `map[K]V`.
+// The same notes about concurrent use as for Map.ToSTDMap are applicable.
+func (m *Typed[K, V]) ToSTDMap() map[K]V {
+	r := map[K]V{}
+	if m.BusySlots() == 0 {
+		return r
+	}
+
+	stor := m.loadSettledStorage()
+	for idxValue := uint64(0); idxValue < stor.size(); idxValue++ {
+		m.visitSlot(&stor.items[idxValue], func(slot *storageSlot[typedEntry[K, V]]) {
+			r[slot.entry.key] = slot.entry.value
+		})
+	}
+
+	return r
+}
+
+func (m *Typed[K, V]) FromSTDMap(stdMap map[K]V) {
+	expectedSize := uint64(float64(len(stdMap))/m.growAtFullness) + 1
+	if expectedSize > m.initialSize {
+		if err := m.growTo(powerOfTwoGE(expectedSize)); err != nil {
+			panic(err)
+		}
+	}
+
+	for k, v := range stdMap {
+		m.Set(k, v)
+	}
+}
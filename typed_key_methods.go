@@ -0,0 +1,210 @@
+package atomicmap
+
+import (
+	"bytes"
+)
+
+// The methods below are the same as Set, Get, Swap and Unset, but for the
+// keys of a known type: the key is not converted to interface{} (except when
+// it's stored into a new slot) and is hashed and compared without the type
+// switches. The keys are the same as the keys passed to the generic methods:
+// a value set by SetByString("a", ...) is found by Get("a") and vice versa.
+//
+// A []byte key is stored as is (not copied), so it should not be modified
+// after it's passed to SetByBytes or SwapByBytes.
+
+func isStringSlotKey(slot *mapSlot, key string) bool {
+	slotKey, ok := slot.entry.key.(string)
+	return ok && slotKey == key
+}
+
+func isBytesSlotKey(slot *mapSlot, key []byte) bool {
+	slotKey, ok := slot.entry.key.([]byte)
+	return ok && bytes.Equal(slotKey, key)
+}
+
+func isUint64SlotKey(slot *mapSlot, key uint64) bool {
+	slotKey, ok := slot.entry.key.(uint64)
+	return ok && slotKey == key
+}
+
+func isUintptrSlotKey(slot *mapSlot, key uintptr) bool {
+	slotKey, ok := slot.entry.key.(uintptr)
+	return ok && slotKey == key
+}
+
+func (m *openAddressGrowingMap) setByPreHash(preHashValue uint64, typeID uint8, preHashValueIsFull bool, compareKey func(*mapSlot) bool, setKey func(*mapSlot), value interface{}) error {
+	return m.set(preHashValue, typeID, preHashValueIsFull, compareKey, setKey, func(slot *mapSlot) bool {
+		slot.entry.setValue(value)
+		return true
+	})
+}
+
+func (m *openAddressGrowingMap) swapByPreHash(preHashValue uint64, typeID uint8, preHashValueIsFull bool, compareKey func(*mapSlot) bool, setKey func(*mapSlot), value interface{}) (oldValue interface{}, err error) {
+	err = m.set(preHashValue, typeID, preHashValueIsFull, compareKey, setKey, func(slot *mapSlot) bool {
+		oldValue = slot.entry.getValue()
+		slot.entry.setValue(value)
+		return true
+	})
+	return
+}
+
+func (m *openAddressGrowingMap) unsetByPreHash(preHashValue uint64, typeID uint8, preHashValueIsFull bool, compareKey func(*mapSlot) bool) error {
+	err := m.unset(func() *mapSlot {
+		return m.lockSlotByPreHash(preHashValue, typeID, preHashValueIsFull, compareKey)
+	}, nil)
+	if err == nil {
+		m.shrinkIfNeeded()
+	}
+	return err
+}
+
+func (m *openAddressGrowingMap) SetByString(key string, value interface{}) error {
+	if err := m.checkKeyType(stringKeyType); err != nil {
+		return err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashString(key)
+	return m.setByPreHash(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return isStringSlotKey(slot, key)
+	}, func(slot *mapSlot) {
+		slot.entry.key = key
+	}, value)
+}
+
+func (m *openAddressGrowingMap) GetByString(key string) (interface{}, error) {
+	if err := m.checkKeyType(stringKeyType); err != nil {
+		return nil, err
+	}
+	if m.BusySlots() == 0 {
+		return nil, NotFound
+	}
+
+	fastKey, fastKeyType, hashValue := m.hashString(key)
+	return m.getByHashValue(fastKey, fastKeyType, hashValue, func(slot *mapSlot) bool {
+		return isStringSlotKey(slot, key)
+	})
+}
+
+func (m *openAddressGrowingMap) SwapByString(key string, value interface{}) (oldValue interface{}, err error) {
+	if err := m.checkKeyType(stringKeyType); err != nil {
+		return nil, err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashString(key)
+	return m.swapByPreHash(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return isStringSlotKey(slot, key)
+	}, func(slot *mapSlot) {
+		slot.entry.key = key
+	}, value)
+}
+
+func (m *openAddressGrowingMap) UnsetByString(key string) error {
+	if err := m.checkKeyType(stringKeyType); err != nil {
+		return err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashString(key)
+	return m.unsetByPreHash(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return isStringSlotKey(slot, key)
+	})
+}
+
+func (m *openAddressGrowingMap) SetByBytes(key []byte, value interface{}) error {
+	if err := m.checkKeyType(bytesKeyType); err != nil {
+		return err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashBytes(key)
+	return m.setByPreHash(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return isBytesSlotKey(slot, key)
+	}, func(slot *mapSlot) {
+		slot.entry.key = key
+	}, value)
+}
+
+func (m *openAddressGrowingMap) SwapByBytes(key []byte, value interface{}) (oldValue interface{}, err error) {
+	if err := m.checkKeyType(bytesKeyType); err != nil {
+		return nil, err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashBytes(key)
+	return m.swapByPreHash(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return isBytesSlotKey(slot, key)
+	}, func(slot *mapSlot) {
+		slot.entry.key = key
+	}, value)
+}
+
+func (m *openAddressGrowingMap) UnsetByBytes(key []byte) error {
+	if err := m.checkKeyType(bytesKeyType); err != nil {
+		return err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashBytes(key)
+	return m.unsetByPreHash(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return isBytesSlotKey(slot, key)
+	})
+}
+
+func (m *openAddressGrowingMap) SetByUint64(key uint64, value interface{}) error {
+	if err := m.checkKeyType(uint64KeyType); err != nil {
+		return err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashUint64(key)
+	return m.setByPreHash(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return isUint64SlotKey(slot, key)
+	}, func(slot *mapSlot) {
+		slot.entry.key = key
+	}, value)
+}
+
+func (m *openAddressGrowingMap) SwapByUint64(key uint64, value interface{}) (oldValue interface{}, err error) {
+	if err := m.checkKeyType(uint64KeyType); err != nil {
+		return nil, err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashUint64(key)
+	return m.swapByPreHash(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return isUint64SlotKey(slot, key)
+	}, func(slot *mapSlot) {
+		slot.entry.key = key
+	}, value)
+}
+
+func (m *openAddressGrowingMap) UnsetByUint64(key uint64) error {
+	if err := m.checkKeyType(uint64KeyType); err != nil {
+		return err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashUint64(key)
+	return m.unsetByPreHash(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return isUint64SlotKey(slot, key)
+	})
+}
+
+func (m *openAddressGrowingMap) SetByUintptr(key uintptr, value interface{}) error {
+	if err := m.checkKeyType(uintptrKeyType); err != nil {
+		return err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashUintptr(key)
+	return m.setByPreHash(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return isUintptrSlotKey(slot, key)
+	}, func(slot *mapSlot) {
+		slot.entry.key = key
+	}, value)
+}
+
+func (m *openAddressGrowingMap) SwapByUintptr(key uintptr, value interface{}) (oldValue interface{}, err error) {
+	if err := m.checkKeyType(uintptrKeyType); err != nil {
+		return nil, err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashUintptr(key)
+	return m.swapByPreHash(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return isUintptrSlotKey(slot, key)
+	}, func(slot *mapSlot) {
+		slot.entry.key = key
+	}, value)
+}
+
+func (m *openAddressGrowingMap) UnsetByUintptr(key uintptr) error {
+	if err := m.checkKeyType(uintptrKeyType); err != nil {
+		return err
+	}
+	preHashValue, typeID, preHashValueIsFull := m.preHashUintptr(key)
+	return m.unsetByPreHash(preHashValue, typeID, preHashValueIsFull, func(slot *mapSlot) bool {
+		return isUintptrSlotKey(slot, key)
+	})
+}
@@ -0,0 +1,234 @@
+package atomicmap
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestTyped(t *testing.T) {
+	m := NewTypedWithArgs[int, string](16)
+
+	if _, err := m.Get(1); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+
+	for i := 0; i < 1024; i++ {
+		if err := m.Set(i, "v"); err != nil {
+			t.Fatalf("Cannot m.Set(%v): %v", i, err)
+		}
+	}
+	oldValue, err := m.Swap(10, "ten")
+	if err != nil || oldValue != "v" {
+		t.Errorf("m.Swap(10): %q %v", oldValue, err)
+	}
+	if m.Len() != 1024 {
+		t.Errorf("m.Len() is not 1024: %v", m.Len())
+	}
+
+	for i := 0; i < 1024; i++ {
+		expectedValue := "v"
+		if i == 10 {
+			expectedValue = "ten"
+		}
+		value, err := m.Get(i)
+		if err != nil || value != expectedValue {
+			t.Errorf("m.Get(%v): %q %v", i, value, err)
+		}
+	}
+
+	if err := m.UnsetIf(10, func(v string) bool { return v == "v" }); err != ConditionFailed {
+		t.Errorf(`An expected "ConditionFailed" error, but got: %v`, err)
+	}
+	if err := m.Unset(10); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+	if err := m.Unset(10); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+	if _, err := m.Get(10); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+
+	stdMap := m.ToSTDMap()
+	if len(stdMap) != m.Len() {
+		t.Errorf("len(stdMap) != m.Len(): %d != %d", len(stdMap), m.Len())
+	}
+	m2 := NewTypedWithArgs[int, string](16)
+	m2.FromSTDMap(stdMap)
+	if m2.Len() != m.Len() {
+		t.Errorf("m2.Len() != m.Len(): %d != %d", m2.Len(), m.Len())
+	}
+}
+
+func TestTypedKeyKinds(t *testing.T) {
+	type myString string
+	mString := NewTypedWithArgs[myString, int](16)
+	mString.Set("a string longer than 8 bytes", 1)
+	mString.Set("short", 2)
+	if v, err := mString.Get("a string longer than 8 bytes"); v != 1 || err != nil {
+		t.Errorf("%v %v", v, err)
+	}
+	if v, err := mString.Get("short"); v != 2 || err != nil {
+		t.Errorf("%v %v", v, err)
+	}
+
+	mFloat := NewTypedWithArgs[float64, int](16)
+	mFloat.Set(0, 1)
+	if v, err := mFloat.Get(math.Copysign(0, -1)); v != 1 || err != nil {
+		t.Errorf("-0 should be found as +0: %v %v", v, err)
+	}
+
+	type structKey struct {
+		A int
+		B string
+	}
+	mStruct := NewTypedWithArgs[structKey, int](16)
+	mStruct.Set(structKey{1, "a"}, 1)
+	if v, err := mStruct.Get(structKey{1, "a"}); v != 1 || err != nil {
+		t.Errorf("%v %v", v, err)
+	}
+	if _, err := mStruct.Get(structKey{1, "b"}); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+}
+
+func TestTypedConcurrency(t *testing.T) {
+	m := NewTypedWithArgs[int, int](4)
+
+	concurrency := 65536
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func(i int) {
+			defer wg.Done()
+			if err := m.Set(i, i); err != nil {
+				t.Errorf("Cannot m.Set(%v, %v): %v", i, i, err)
+			}
+			r, err := m.Get(i)
+			if err != nil || r != i {
+				t.Errorf("m.Get(%v): %v %v", i, r, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestTypedClear(t *testing.T) {
+	m := NewTypedWithArgs[int, int](16)
+	for i := 0; i < 1000; i++ {
+		m.Set(i, i)
+	}
+	size := m.size()
+
+	m.Clear()
+	if m.Len() != 0 || m.size() != size {
+		t.Errorf("m.Len() == %v, m.size() == %v", m.Len(), m.size())
+	}
+	if _, err := m.Get(500); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+
+	m.Set(1, 2)
+	m.Reset()
+	if m.Len() != 0 || m.size() != 16 {
+		t.Errorf("m.Len() == %v, m.size() == %v", m.Len(), m.size())
+	}
+	m.Set(1, 3)
+	if v, err := m.Get(1); err != nil || v != 3 {
+		t.Errorf("m.Get(1): %v %v", v, err)
+	}
+}
+
+func TestTypedShrink(t *testing.T) {
+	m := NewTypedWithArgs[int, int](16)
+	m.SetShrinkAtFullness(0.1)
+	for i := 0; i < 10000; i++ {
+		m.Set(i, i)
+	}
+	for i := 100; i < 10000; i++ {
+		m.Unset(i)
+	}
+	if m.size() > 512 {
+		t.Errorf("The storage was not shrunk: %v", m.size())
+	}
+
+	if err := m.ShrinkTo(64); err != NoSpaceLeft {
+		t.Errorf(`An expected "NoSpaceLeft" error, but got: %v`, err)
+	}
+	if err := m.ShrinkTo(200); err != nil {
+		t.Errorf("Got an unexpected error: %v", err)
+	}
+	if m.size() != 256 || m.Len() != 100 {
+		t.Errorf("m.size() == %v, m.Len() == %v", m.size(), m.Len())
+	}
+	for i := 0; i < 100; i++ {
+		if v, err := m.Get(i); err != nil || v != i {
+			t.Errorf("m.Get(%v): %v %v", i, v, err)
+		}
+	}
+}
+
+func TestTypedTombstones(t *testing.T) {
+	m := NewTypedWithArgs[int, int](1024)
+	for i := 0; i < 1000000; i++ {
+		m.Set(i, i)
+		if i >= 100 {
+			m.Unset(i - 100)
+		}
+	}
+	if m.Len() != 100 || m.size() != 1024 {
+		t.Errorf("m.Len() == %v, m.size() == %v", m.Len(), m.size())
+	}
+	if occupied := m.occupiedSlots(); float64(occupied)/float64(m.size()) >= m.growAtFullness {
+		t.Errorf("Too many occupied slots: %v", occupied)
+	}
+	if _, err := m.Get(0); err != NotFound {
+		t.Errorf(`An expected "NotFound" error, but got: %v`, err)
+	}
+}
+
+func TestTypedIncrementalResize(t *testing.T) {
+	m := NewTypedWithArgs[int, int](1024)
+
+	keyAmount := 0
+	for m.loadStorage().loadNext() == nil {
+		m.Set(keyAmount, keyAmount)
+		keyAmount++
+	}
+	oldStorage := m.loadStorage()
+	if oldStorage.migratedCount >= oldStorage.size() {
+		t.Errorf("The whole storage was moved at once")
+	}
+
+	// Modifying the keys during the migration
+	m.Set(0, -1)
+	m.Unset(1)
+	for i := 0; i < keyAmount; i++ {
+		v, err := m.Get(i)
+		switch i {
+		case 0:
+			if v != -1 {
+				t.Errorf("m.Get(0): %v %v", v, err)
+			}
+		case 1:
+			if err != NotFound {
+				t.Errorf(`An expected "NotFound" error, but got: %v %v`, v, err)
+			}
+		default:
+			if err != nil || v != i {
+				t.Errorf("m.Get(%v): %v %v", i, v, err)
+			}
+		}
+	}
+
+	for i := 0; m.loadStorage().loadNext() != nil; i++ {
+		m.Set(-i-1, i)
+	}
+	if m.loadStorage() == oldStorage || m.size() != 2048 {
+		t.Errorf("The new storage didn't become the current one: %v", m.size())
+	}
+	if len(m.ToSTDMap()) != m.Len() {
+		t.Errorf("len(m.ToSTDMap()) != m.Len(): %v %v", len(m.ToSTDMap()), m.Len())
+	}
+}